@@ -1,12 +1,10 @@
 package app
 
 import (
-	"errors"
+	"fmt"
 	"net/http"
-	"net/url"
-	_path "path"
-	"path/filepath"
 	"sync"
+	"time"
 
 	zeroapi "github.com/zerogo-hub/zero-api"
 	zeroctx "github.com/zerogo-hub/zero-api/context"
@@ -71,6 +69,8 @@ func NewApp(opts ...Option) zeroapi.App {
 		opt(a.config)
 	}
 
+	a.router.SetMode(a.config.routerMode)
+
 	return a
 }
 
@@ -124,6 +124,36 @@ func (a *app) CookieDecodeHandler() zeroapi.CookieDecodeHandler {
 	return a.config.cookieDecode
 }
 
+// IsHandleMethodNotAllowed 路由未匹配时，是否检查其它 Method 并返回 405
+func (a *app) IsHandleMethodNotAllowed() bool {
+	return a.config.handleMethodNotAllowed
+}
+
+// MethodNotAllowedHandler 获取自定义的 405 响应函数，未设置时返回 nil
+func (a *app) MethodNotAllowedHandler() zeroapi.Handler {
+	return a.config.methodNotAllowedHandler
+}
+
+// APIVersionQuery 获取请求的 API 版本使用的查询参数，为空时不使用查询参数
+func (a *app) APIVersionQuery() string {
+	return a.config.apiVersionQuery
+}
+
+// DefaultAPIVersion 请求未指定 API 版本时使用的版本
+func (a *app) DefaultAPIVersion() string {
+	return a.config.defaultAPIVersion
+}
+
+// IsStrictAPIVersion 请求的 API 版本不存在时，是否返回 406
+func (a *app) IsStrictAPIVersion() bool {
+	return a.config.strictAPIVersion
+}
+
+// TimeoutStatus 路由超时时返回的状态码，默认 503
+func (a *app) TimeoutStatus() int {
+	return a.config.timeoutStatus
+}
+
 // Use 添加 App 级别 中间件，每一次路由都会调用公共中间件
 func (a *app) Use(handlers ...zeroapi.Handler) {
 	for _, handler := range handlers {
@@ -146,8 +176,8 @@ func (a *app) ExecuteMiddlewares(ctx zeroapi.Context) {
 // Run 启动服务，此方法会阻塞，直到应用关闭
 // addr: host:port，例如: ":8080"，"192.168.1.8:80"
 func (a *app) Run(addr string) error {
-	if !a.Router().Build() {
-		return errors.New("router build failed")
+	if err := a.Router().Build(); err != nil {
+		return fmt.Errorf("router build failed: %w", err)
 	}
 
 	if err := a.server.Start(addr); err != nil {
@@ -172,7 +202,7 @@ func (a *app) Prefix(prefix string) zeroapi.App {
 // path: 路径，以 "/" 开头，不可以为空
 // handlers: 路由级别中间件和处理函数
 func (a *app) Get(path string, handlers ...zeroapi.Handler) zeroapi.App {
-	a.router.Register(zeroapi.MethodGet, path, handlers...)
+	a.register(zeroapi.MethodGet, path, handlers...)
 	return a
 }
 
@@ -180,7 +210,7 @@ func (a *app) Get(path string, handlers ...zeroapi.Handler) zeroapi.App {
 // path: 路径，以 "/" 开头，不可以为空
 // handlers: 路由级别中间件和处理函数
 func (a *app) Post(path string, handlers ...zeroapi.Handler) zeroapi.App {
-	a.router.Register(zeroapi.MethodPost, path, handlers...)
+	a.register(zeroapi.MethodPost, path, handlers...)
 	return a
 }
 
@@ -188,7 +218,7 @@ func (a *app) Post(path string, handlers ...zeroapi.Handler) zeroapi.App {
 // path: 路径，以 "/" 开头，不可以为空
 // handlers: 路由级别中间件和处理函数
 func (a *app) Put(path string, handlers ...zeroapi.Handler) zeroapi.App {
-	a.router.Register(zeroapi.MethodPut, path, handlers...)
+	a.register(zeroapi.MethodPut, path, handlers...)
 	return a
 }
 
@@ -196,7 +226,7 @@ func (a *app) Put(path string, handlers ...zeroapi.Handler) zeroapi.App {
 // path: 路径，以 "/" 开头，不可以为空
 // handlers: 路由级别中间件和处理函数
 func (a *app) Delete(path string, handlers ...zeroapi.Handler) zeroapi.App {
-	a.router.Register(zeroapi.MethodDelete, path, handlers...)
+	a.register(zeroapi.MethodDelete, path, handlers...)
 	return a
 }
 
@@ -204,7 +234,7 @@ func (a *app) Delete(path string, handlers ...zeroapi.Handler) zeroapi.App {
 // path: 路径，以 "/" 开头，不可以为空
 // handlers: 路由级别中间件和处理函数
 func (a *app) Head(path string, handlers ...zeroapi.Handler) zeroapi.App {
-	a.router.Register(zeroapi.MethodHead, path, handlers...)
+	a.register(zeroapi.MethodHead, path, handlers...)
 	return a
 }
 
@@ -212,7 +242,7 @@ func (a *app) Head(path string, handlers ...zeroapi.Handler) zeroapi.App {
 // path: 路径，以 "/" 开头，不可以为空
 // handlers: 路由级别中间件和处理函数
 func (a *app) Patch(path string, handlers ...zeroapi.Handler) zeroapi.App {
-	a.router.Register(zeroapi.MethodPatch, path, handlers...)
+	a.register(zeroapi.MethodPatch, path, handlers...)
 	return a
 }
 
@@ -220,7 +250,66 @@ func (a *app) Patch(path string, handlers ...zeroapi.Handler) zeroapi.App {
 // path: 路径，以 "/" 开头，不可以为空
 // handlers: 路由级别中间件和处理函数
 func (a *app) Options(path string, handlers ...zeroapi.Handler) zeroapi.App {
-	a.router.Register(zeroapi.MethodOptions, path, handlers...)
+	a.register(zeroapi.MethodOptions, path, handlers...)
+	return a
+}
+
+// Any 同时注册 GET, POST, PUT, DELETE, HEAD, PATCH, OPTIONS
+// path: 路径，以 "/" 开头，不可以为空
+// handlers: 路由级别中间件和处理函数
+func (a *app) Any(path string, handlers ...zeroapi.Handler) zeroapi.App {
+	a.register(zeroapi.MethodAny, path, handlers...)
+	return a
+}
+
+// Handle 注册任意 Method，包括 CONNECT，TRACE 以及自定义 Method，例如 PROPFIND
+// method: HTTP Method，zeroapi.MethodAny 与 Any 相同
+// path: 路径，以 "/" 开头，不可以为空
+// handlers: 路由级别中间件和处理函数
+func (a *app) Handle(method, path string, handlers ...zeroapi.Handler) zeroapi.App {
+	a.register(method, path, handlers...)
+	return a
+}
+
+// Match 同时注册多个 Method，Name 和 Meta 作用于所有 Method
+// 例如: a.Match([]string{zeroapi.MethodGet, zeroapi.MethodPost}, "/login", handler)
+func (a *app) Match(methods []string, path string, handlers ...zeroapi.Handler) zeroapi.App {
+	a.match(methods, path, handlers...)
+	return a
+}
+
+// register 注册路由，严格模式下注册失败会 panic，否则错误会在 Build 时返回
+func (a *app) register(method, path string, handlers ...zeroapi.Handler) {
+	a.match([]string{method}, path, handlers...)
+}
+
+func (a *app) match(methods []string, path string, handlers ...zeroapi.Handler) {
+	err := a.router.Match(methods, path, handlers...)
+	if err != nil && a.router.Mode()&zeroapi.RouterModeStrict != 0 {
+		panic(err)
+	}
+}
+
+// Name 为最近一次注册的路由命名，用于 Router().URL 生成路径
+// 例如: a.Get("/blog/:id", handler).Name("blog")
+func (a *app) Name(name string) zeroapi.App {
+	if !a.router.Name(name) {
+		a.Logger().Errorf("route name \"%s\" is ignored, it is empty, duplicated, or the route is not registered", name)
+	}
+	return a
+}
+
+// Meta 为最近一次注册的路由添加元数据，匹配成功后通过 ctx.Route().Meta(key) 获取
+// 例如: a.Get("/admin", handler).Meta("perm", "admin")
+func (a *app) Meta(key string, value interface{}) zeroapi.App {
+	a.router.Meta(key, value)
+	return a
+}
+
+// Timeout 为最近一次注册的路由设置超时时间，超时后请求的 context 被取消，并返回 503
+// 例如: a.Get("/report", handler).Timeout(3 * time.Second)
+func (a *app) Timeout(timeout time.Duration) zeroapi.App {
+	a.router.Timeout(timeout)
 	return a
 }
 
@@ -229,28 +318,29 @@ func (a *app) Group(path string) zeroapi.Group {
 	return zerorouter.NewGroup(a, path)
 }
 
+// Host 创建域名路由实例，只有请求的域名匹配 pattern 时才会使用其中的路由
+// pattern 中可以使用 {name} 匹配一级域名，通过 ctx.Dynamic("name") 获取
+// 例如: a.Host("{tenant}.example.com").Get("/user", handler)
+func (a *app) Host(pattern string) zeroapi.Group {
+	return zerorouter.NewHostGroup(a, pattern)
+}
+
+// APIVersion 创建 API 版本组路由，其中的路由只有请求的 API 版本匹配时才会使用
+// 请求的版本来自 Accept-Version 请求头，Accept 中的 application/vnd.{vendor}.v{version}+json，或者查询参数
+// 例如: a.APIVersion("2").Get("/users", handler)
+func (a *app) APIVersion(version string) zeroapi.Group {
+	return zerorouter.NewVersionGroup(a, version)
+}
+
+// When 创建带有匹配条件的组路由，路径匹配之后检查条件，全部满足时才会使用其中的路由
+// 例如: a.When(zeroapi.ContentType("application/x-protobuf")).Post("/rpc", handler)
+func (a *app) When(predicates ...zeroapi.RoutePredicate) zeroapi.Group {
+	return zerorouter.NewGroup(a, "").When(predicates...)
+}
+
 // Static 添加静态资源服务
 // prefix 静态资源路由前缀
 // path 资源真实位置(绝对路径，相对路径)
 func (a *app) Static(prefix, path string) {
-	if path == "" {
-		path = "."
-	}
-
-	f := func(ctx zeroapi.Context) {
-		fileName, err := url.PathUnescape(ctx.Dynamic("*"))
-		if fileName == "" || err != nil {
-			ctx.NotFound()
-			return
-		}
-		path := filepath.Join(path, _path.Clean("/"+fileName))
-		ctx.DownloadFile(path, fileName)
-	}
-
-	if prefix == "/" {
-		a.Get(prefix+"*", f)
-		return
-	}
-
-	a.Get(prefix+"/*", f)
+	zerorouter.NewGroup(a, "").Static(prefix, path)
 }
@@ -1,6 +1,8 @@
 package app
 
 import (
+	"net/http"
+
 	zeroapi "github.com/zerogo-hub/zero-api"
 
 	zerologger "github.com/zerogo-hub/zero-helper/logger"
@@ -28,6 +30,27 @@ type config struct {
 
 	// cookieDecode 对 cookie 键值解码函数
 	cookieDecode zeroapi.CookieDecodeHandler
+
+	// handleMethodNotAllowed 路由未匹配时，是否检查其它 Method 并返回 405，默认开启
+	handleMethodNotAllowed bool
+
+	// methodNotAllowedHandler 自定义 405 响应内容
+	methodNotAllowedHandler zeroapi.Handler
+
+	// routerMode 路由模式，见 zeroapi.RouterModeXxx
+	routerMode int
+
+	// apiVersionQuery 从该查询参数中获取请求的 API 版本，为空时不使用查询参数
+	apiVersionQuery string
+
+	// defaultAPIVersion 请求未指定 API 版本时使用的版本
+	defaultAPIVersion string
+
+	// strictAPIVersion 请求指定的 API 版本不存在时返回 406，不使用默认路由
+	strictAPIVersion bool
+
+	// timeoutStatus 路由超时时返回的状态码
+	timeoutStatus int
 }
 
 func defaultConfig() *config {
@@ -35,6 +58,9 @@ func defaultConfig() *config {
 		version:   zeroapi.VERSION,
 		maxMemory: defaultMaxMemory,
 		logger:    zerologger.NewSampleLogger(),
+
+		handleMethodNotAllowed: true,
+		timeoutStatus:          http.StatusServiceUnavailable,
 	}
 }
 
@@ -69,3 +95,97 @@ func WithCookieHandler(encoder zeroapi.CookieEncodeHandler, decoder zeroapi.Cook
 		config.cookieDecode = decoder
 	}
 }
+
+// WithHandleMethodNotAllowed 路由未匹配时，是否检查其它 Method
+// 开启后，若其它 Method 能够匹配，则返回 405，并在 Allow 中列出这些 Method
+func WithHandleMethodNotAllowed(enable bool) Option {
+	return func(config *config) {
+		config.handleMethodNotAllowed = enable
+	}
+}
+
+// WithMethodNotAllowedHandler 自定义 405 响应内容
+// 调用该函数前，已经设置好 Allow 响应头和 405 状态码
+func WithMethodNotAllowedHandler(handler zeroapi.Handler) Option {
+	return func(config *config) {
+		config.methodNotAllowedHandler = handler
+	}
+}
+
+// WithAutoHeadOptions 自动处理未注册的 HEAD 和 OPTIONS 请求
+// HEAD 使用对应的 GET 路由处理，丢弃响应内容，保留 Content-Length
+// OPTIONS 返回 204，并在 Allow 中列出能够匹配的 Method
+// 已注册的 HEAD，OPTIONS 路由优先，不受影响
+func WithAutoHeadOptions(enable bool) Option {
+	return withRouterMode(zeroapi.RouterModeAutoHead|zeroapi.RouterModeAutoOptions, enable)
+}
+
+// WithRedirectTrailingSlash 路由未匹配时，尝试添加或者去除末尾的 "/"
+// 匹配成功则重定向到该路径，GET 使用 301，其它 Method 使用 308
+func WithRedirectTrailingSlash(enable bool) Option {
+	return withRouterMode(zeroapi.RouterModeRedirectTrailingSlash, enable)
+}
+
+// WithRedirectFixedPath 路由未匹配时，清理路径中多余的 "/"，"."，".."
+// 匹配成功则重定向到该路径，GET 使用 301，其它 Method 使用 308
+func WithRedirectFixedPath(enable bool) Option {
+	return withRouterMode(zeroapi.RouterModeRedirectFixedPath, enable)
+}
+
+// WithStrictRouting 严格模式，注册路由时立即完整检查路由，包括验证函数是否已注册
+// 通过 App，Group 注册路由失败时 panic，错误类型为 *zeroapi.RouteError
+// 验证函数需要在注册路由之前注册
+func WithStrictRouting(enable bool) Option {
+	return withRouterMode(zeroapi.RouterModeStrict, enable)
+}
+
+// WithUseRawPath 使用未解码的路径 URL.EscapedPath() 匹配路由
+// 例如 /objects/a%2Fb 可以匹配 /objects/:key，key = "a/b"
+// 动态参数的值分别解码后存入 ctx.Dynamic，通配符的值保持请求中的原样，不解码
+// 含有非 ASCII 字符的静态路由需要使用编码后的形式注册
+func WithUseRawPath(enable bool) Option {
+	return withRouterMode(zeroapi.RouterModeUseRawPath, enable)
+}
+
+// WithAPIVersionQuery 从查询参数中获取请求的 API 版本，例如 "api-version"，优先级低于请求头
+func WithAPIVersionQuery(name string) Option {
+	return func(config *config) {
+		config.apiVersionQuery = name
+	}
+}
+
+// WithDefaultAPIVersion 请求未指定 API 版本时使用的版本，版本路由未匹配时仍然会使用默认路由
+func WithDefaultAPIVersion(version string) Option {
+	return func(config *config) {
+		config.defaultAPIVersion = version
+	}
+}
+
+// WithStrictAPIVersion 请求指定的 API 版本不存在时返回 406
+// 版本存在但是路由未匹配时，返回 404 或者 405，不使用默认路由
+// 请求未指定版本时不受影响
+func WithStrictAPIVersion(enable bool) Option {
+	return func(config *config) {
+		config.strictAPIVersion = enable
+	}
+}
+
+// WithTimeoutStatus 路由超时时返回的状态码，默认 503，网关场景可以使用 504
+// 见 App.Timeout，Group.UseTimeout
+func WithTimeoutStatus(code int) Option {
+	return func(config *config) {
+		if code > 0 {
+			config.timeoutStatus = code
+		}
+	}
+}
+
+func withRouterMode(mode int, enable bool) Option {
+	return func(config *config) {
+		if enable {
+			config.routerMode |= mode
+		} else {
+			config.routerMode &^= mode
+		}
+	}
+}
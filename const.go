@@ -36,6 +36,27 @@ const (
 	MethodAny = "ANY"
 )
 
+const (
+	// RouterModeAutoHead HEAD 请求未注册路由时，使用对应的 GET 路由处理，丢弃响应内容，保留 Content-Length
+	RouterModeAutoHead = 1 << iota
+
+	// RouterModeAutoOptions OPTIONS 请求未注册路由时，自动返回 204，并在 Allow 中列出能够匹配的 Method
+	RouterModeAutoOptions
+
+	// RouterModeRedirectTrailingSlash 路由未匹配时，尝试添加或者去除末尾的 "/"，匹配成功则重定向
+	RouterModeRedirectTrailingSlash
+
+	// RouterModeRedirectFixedPath 路由未匹配时，清理路径中多余的 "/"，"."，".."，匹配成功则重定向
+	RouterModeRedirectFixedPath
+
+	// RouterModeStrict 严格模式，注册路由时立即检查验证函数，App 和 Group 注册路由失败时 panic
+	RouterModeStrict
+
+	// RouterModeUseRawPath 使用未解码的路径 URL.EscapedPath() 匹配路由，%2F 不会被视为路径分隔符
+	// 动态参数的值分别解码，通配符的值保持原样，不解码
+	RouterModeUseRawPath
+)
+
 // AllMethods 所有 HTTP Method
 func AllMethods() []string {
 	return []string{
@@ -61,6 +82,10 @@ type (
 	// RouterValidator 验证函数
 	RouterValidator func(s string) bool
 
+	// RouterValidatorFactory 带参数的验证函数的生成函数，在路由解析时调用
+	// 例如 |len(3,16)|，args = ["3", "16"]
+	RouterValidatorFactory func(args ...string) (RouterValidator, error)
+
 	// CookieEncodeHandler cookie 编码与解码函数
 	CookieEncodeHandler func(s string) string
 
@@ -31,12 +31,21 @@ type context struct {
 	// responseSize 响应内容大小
 	responseSize int64
 
-	// dynamic 存储动态参数的值
+	// params 存储动态参数的值，在请求之间复用
 	// 示例:
 	// 定义路由: /blog/:id
 	// 调用路由: /blog/1001
-	// dynamics = {id: "1001"}
-	dynamics map[string]string
+	// params = [{id 1001}]
+	params zeroapi.Params
+
+	// query 缓存解析后的查询参数，见 Get
+	query url.Values
+
+	// rawQuery 解析 query 时使用的原始查询参数，请求被修改时重新解析
+	rawQuery string
+
+	// route 匹配成功的路由
+	route zeroapi.MatchedRoute
 
 	// values 玩家自定义数据
 	values map[string]interface{}
@@ -67,6 +76,9 @@ func (ctx *context) Reset(res http.ResponseWriter, req *http.Request) {
 	ctx.status = ContextStatusNormal
 	ctx.httpCode = http.StatusOK
 
+	ctx.route = nil
+	ctx.params = ctx.params[:0]
+	ctx.query = nil
 	ctx.afters = nil
 	ctx.ends = nil
 }
@@ -75,6 +87,10 @@ func (ctx *context) Request() *http.Request {
 	return ctx.req
 }
 
+func (ctx *context) SetRequest(req *http.Request) {
+	ctx.req = req
+}
+
 func (ctx *context) Response() zeroapi.Writer {
 	return ctx.res
 }
@@ -87,6 +103,14 @@ func (ctx *context) Path() string {
 	return ctx.req.RequestURI
 }
 
+func (ctx *context) Route() zeroapi.MatchedRoute {
+	return ctx.route
+}
+
+func (ctx *context) SetRoute(route zeroapi.MatchedRoute) {
+	ctx.route = route
+}
+
 func (ctx *context) HTTPCode() int {
 	return ctx.httpCode
 }
@@ -165,6 +189,20 @@ func (ctx *context) NotFound() {
 	}
 }
 
+func (ctx *context) MethodNotAllowed() {
+	ctx.SetHTTPCode(http.StatusMethodNotAllowed)
+	if _, err := ctx.Message(http.StatusMethodNotAllowed, "METHOD NOT ALLOWED"); err != nil {
+		ctx.App().Logger().Errorf("set message failed, err: %s", err.Error())
+	}
+}
+
+func (ctx *context) NotAcceptable() {
+	ctx.SetHTTPCode(http.StatusNotAcceptable)
+	if _, err := ctx.Message(http.StatusNotAcceptable, "NOT ACCEPTABLE"); err != nil {
+		ctx.App().Logger().Errorf("set message failed, err: %s", err.Error())
+	}
+}
+
 func (ctx *context) IsStopped() bool {
 	return ctx.status == ContextStatusStopped
 }
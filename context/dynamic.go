@@ -2,6 +2,8 @@ package context
 
 import (
 	"errors"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
 )
 
 func (ctx *context) Dynamic(key string) string {
@@ -14,13 +16,9 @@ func (ctx *context) Dynamic(key string) string {
 		key = key[1:]
 	}
 
-	if ctx.dynamics != nil {
-		if value, exist := ctx.dynamics[key]; exist {
-			return value
-		}
-	}
+	value, _ := ctx.params.Get(key)
 
-	return ""
+	return value
 }
 
 func (ctx *context) SetDynamic(key string, value string) error {
@@ -33,15 +31,68 @@ func (ctx *context) SetDynamic(key string, value string) error {
 		key = key[1:]
 	}
 
-	if ctx.dynamics == nil {
-		ctx.dynamics = make(map[string]string)
-	}
-
-	ctx.dynamics[key] = value
+	ctx.params.Set(key, value)
 
 	return nil
 }
 
 func (ctx *context) SetDynamics(dynamics map[string]string) {
-	ctx.dynamics = dynamics
+	ctx.params = ctx.params[:0]
+
+	for key, value := range dynamics {
+		ctx.params = append(ctx.params, zeroapi.Param{Key: key, Value: value})
+	}
+}
+
+func (ctx *context) Params() *zeroapi.Params {
+	return &ctx.params
+}
+
+func (ctx *context) DynamicInt(key string) int {
+	v, _ := zeroapi.DynamicAs[int](ctx, key)
+	return v
+}
+
+func (ctx *context) DynamicInt64(key string) int64 {
+	v, _ := zeroapi.DynamicAs[int64](ctx, key)
+	return v
+}
+
+func (ctx *context) DynamicUint64(key string) uint64 {
+	v, _ := zeroapi.DynamicAs[uint64](ctx, key)
+	return v
+}
+
+func (ctx *context) DynamicBool(key string) bool {
+	v, _ := zeroapi.DynamicAs[bool](ctx, key)
+	return v
+}
+
+func (ctx *context) DynamicFloat64(key string) float64 {
+	v, _ := zeroapi.DynamicAs[float64](ctx, key)
+	return v
+}
+
+func (ctx *context) DynamicDefault(key, def string) string {
+	return zeroapi.DynamicAsDefault(ctx, key, def)
+}
+
+func (ctx *context) DynamicIntDefault(key string, def int) int {
+	return zeroapi.DynamicAsDefault(ctx, key, def)
+}
+
+func (ctx *context) DynamicInt64Default(key string, def int64) int64 {
+	return zeroapi.DynamicAsDefault(ctx, key, def)
+}
+
+func (ctx *context) DynamicUint64Default(key string, def uint64) uint64 {
+	return zeroapi.DynamicAsDefault(ctx, key, def)
+}
+
+func (ctx *context) DynamicBoolDefault(key string, def bool) bool {
+	return zeroapi.DynamicAsDefault(ctx, key, def)
+}
+
+func (ctx *context) DynamicFloat64Default(key string, def float64) float64 {
+	return zeroapi.DynamicAsDefault(ctx, key, def)
 }
@@ -1,15 +1,26 @@
 package context
 
 import (
+	"net/url"
 	"strconv"
 )
 
+// urlQuery 解析 URL 中的查询参数，同一个请求只解析一次，中间件修改了查询参数时重新解析
+func (ctx *context) urlQuery() url.Values {
+	if ctx.query == nil || ctx.rawQuery != ctx.req.URL.RawQuery {
+		ctx.query = ctx.req.URL.Query()
+		ctx.rawQuery = ctx.req.URL.RawQuery
+	}
+
+	return ctx.query
+}
+
 func (ctx *context) Get(key string) string {
-	return ctx.req.URL.Query().Get(key)
+	return ctx.urlQuery().Get(key)
 }
 
 func (ctx *context) Gets(key string) []string {
-	return ctx.req.URL.Query()[key]
+	return ctx.urlQuery()[key]
 }
 
 func (ctx *context) GetEscape(key string) string {
@@ -0,0 +1,104 @@
+package zeroapi
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// DynamicType 动态参数可以转换的类型
+type DynamicType interface {
+	string | bool |
+		int | int8 | int16 | int32 | int64 |
+		uint | uint8 | uint16 | uint32 | uint64 |
+		float32 | float64
+}
+
+// DynamicAs 获取动态参数的值，并将结果转为 T，参数不存在或者转换失败时返回错误
+// 参数不存在时返回 ErrDynamicNotFound，参数存在但值为空时按照 T 转换，例如未提供的可选参数，string 返回 "" 和 nil
+// 示例:
+// 定义路由: /blog/:id(^\d+$)
+// 调用路由: /blog/1001
+// id, err := zeroapi.DynamicAs[int64](ctx, "id") -> 1001, nil
+func DynamicAs[T DynamicType](ctx ContextDynamic, key string) (T, error) {
+	var result T
+
+	value, ok := lookupDynamic(ctx, key)
+	if !ok {
+		return result, fmt.Errorf("%w: \"%s\"", ErrDynamicNotFound, key)
+	}
+
+	var err error
+
+	switch p := any(&result).(type) {
+	case *string:
+		*p = value
+	case *bool:
+		*p, err = strconv.ParseBool(value)
+	case *int:
+		*p, err = parseInt[int](value, strconv.IntSize)
+	case *int8:
+		*p, err = parseInt[int8](value, 8)
+	case *int16:
+		*p, err = parseInt[int16](value, 16)
+	case *int32:
+		*p, err = parseInt[int32](value, 32)
+	case *int64:
+		*p, err = parseInt[int64](value, 64)
+	case *uint:
+		*p, err = parseUint[uint](value, strconv.IntSize)
+	case *uint8:
+		*p, err = parseUint[uint8](value, 8)
+	case *uint16:
+		*p, err = parseUint[uint16](value, 16)
+	case *uint32:
+		*p, err = parseUint[uint32](value, 32)
+	case *uint64:
+		*p, err = parseUint[uint64](value, 64)
+	case *float32:
+		var v float64
+		v, err = strconv.ParseFloat(value, 32)
+		*p = float32(v)
+	case *float64:
+		*p, err = strconv.ParseFloat(value, 64)
+	}
+
+	if err != nil {
+		var zero T
+		return zero, fmt.Errorf("dynamic parameter \"%s\": %w", key, err)
+	}
+
+	return result, nil
+}
+
+// DynamicAsDefault 获取动态参数的值，并将结果转为 T，参数不存在或者转换失败时返回默认值 def
+func DynamicAsDefault[T DynamicType](ctx ContextDynamic, key string, def T) T {
+	if result, err := DynamicAs[T](ctx, key); err == nil {
+		return result
+	}
+
+	return def
+}
+
+// lookupDynamic 获取动态参数的值，第二个返回值表示参数是否存在，key 的格式为 "param" 或者 ":param"
+func lookupDynamic(ctx ContextDynamic, key string) (string, bool) {
+	if key != "" && key[0] == ':' {
+		key = key[1:]
+	}
+
+	params := ctx.Params()
+	if key == "" || params == nil {
+		return "", false
+	}
+
+	return params.Get(key)
+}
+
+func parseInt[T int | int8 | int16 | int32 | int64](value string, bitSize int) (T, error) {
+	v, err := strconv.ParseInt(value, 10, bitSize)
+	return T(v), err
+}
+
+func parseUint[T uint | uint8 | uint16 | uint32 | uint64](value string, bitSize int) (T, error) {
+	v, err := strconv.ParseUint(value, 10, bitSize)
+	return T(v), err
+}
@@ -0,0 +1,100 @@
+package zeroapi
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrInvalidPath 路径为空或者没有处理函数
+	ErrInvalidPath = errors.New("invalid path")
+
+	// ErrInvalidMethod Method 为空或者含有非法字符
+	ErrInvalidMethod = errors.New("invalid method")
+
+	// ErrInvalidSegment 路径片段格式错误，例如缺少参数名称，可选参数之后含有必选参数
+	ErrInvalidSegment = errors.New("invalid segment")
+
+	// ErrUnbalanced 正则表达式的 () 或者验证函数的 || 不成对
+	ErrUnbalanced = errors.New("unbalanced () or ||")
+
+	// ErrInvalidRegexp 正则表达式无法编译
+	ErrInvalidRegexp = errors.New("invalid regexp")
+
+	// ErrValidatorNotFound 验证函数或者验证函数的生成函数未注册
+	ErrValidatorNotFound = errors.New("validator not found")
+
+	// ErrInvalidValidator 验证函数参数错误，例如 len(a)
+	ErrInvalidValidator = errors.New("invalid validator")
+
+	// ErrDuplicateRoute 相同 Method 的路由重复注册
+	ErrDuplicateRoute = errors.New("duplicate route")
+
+	// ErrDuplicateName 路由名称重复
+	ErrDuplicateName = errors.New("duplicate route name")
+
+	// ErrWildcardNotLast 通配符不是最后一个片段
+	ErrWildcardNotLast = errors.New("wildcard must be the last segment")
+
+	// ErrParamConflict 同一位置的动态参数只有名称不同，例如 /user/:id 与 /user/:name
+	ErrParamConflict = errors.New("conflicting param names")
+
+	// ErrRouteNotFound 删除路由时，路由不存在
+	ErrRouteNotFound = errors.New("route not found")
+
+	// ErrDynamicNotFound 获取动态参数时，参数不存在
+	ErrDynamicNotFound = errors.New("dynamic parameter not found")
+)
+
+// RouteError 路由注册，解析时的错误，可以使用 errors.Is(err, ErrXxx) 判断错误类型
+type RouteError struct {
+	// Method HTTP Method
+	Method string
+
+	// Host 域名，默认路由表为 ""
+	Host string
+
+	// Path 路由全路径
+	Path string
+
+	// Segment 出错的路径片段，例如 /:id(^\d+$
+	Segment string
+
+	// Err 具体错误
+	Err error
+}
+
+// Error 实现 error 接口
+// 示例: route GET /blog/:id(^\d+$: segment "/:id(^\d+$": unbalanced () or ||
+func (e *RouteError) Error() string {
+	var b strings.Builder
+
+	b.WriteString("route")
+	if e.Method != "" {
+		b.WriteString(" ")
+		b.WriteString(e.Method)
+	}
+	if e.Host != "" || e.Path != "" {
+		b.WriteString(" ")
+		b.WriteString(e.Host)
+		b.WriteString(e.Path)
+	}
+
+	if e.Segment != "" {
+		b.WriteString(": segment \"")
+		b.WriteString(e.Segment)
+		b.WriteString("\"")
+	}
+
+	if e.Err != nil {
+		b.WriteString(": ")
+		b.WriteString(e.Err.Error())
+	}
+
+	return b.String()
+}
+
+// Unwrap 用于 errors.Is，errors.As
+func (e *RouteError) Unwrap() error {
+	return e.Err
+}
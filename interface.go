@@ -1,8 +1,11 @@
 package zeroapi
 
 import (
+	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
+	"time"
 
 	zerograceful "github.com/zerogo-hub/zero-helper/graceful/http"
 	zerologger "github.com/zerogo-hub/zero-helper/logger"
@@ -41,6 +44,24 @@ type App interface {
 	// CookieDecodeHandler 获取 cookie 解码函数
 	CookieDecodeHandler() CookieDecodeHandler
 
+	// IsHandleMethodNotAllowed 路由未匹配时，是否检查其它 Method 并返回 405
+	IsHandleMethodNotAllowed() bool
+
+	// MethodNotAllowedHandler 获取自定义的 405 响应函数，未设置时返回 nil
+	MethodNotAllowedHandler() Handler
+
+	// APIVersionQuery 获取请求的 API 版本使用的查询参数，为空时不使用查询参数
+	APIVersionQuery() string
+
+	// DefaultAPIVersion 请求未指定 API 版本时使用的版本
+	DefaultAPIVersion() string
+
+	// IsStrictAPIVersion 请求的 API 版本不存在时，是否返回 406
+	IsStrictAPIVersion() bool
+
+	// TimeoutStatus 路由超时时返回的状态码，默认 503
+	TimeoutStatus() int
+
 	// Use 添加 App 级别 中间件，每一次路由都会调用公共中间件
 	Use(handlers ...Handler)
 
@@ -95,9 +116,51 @@ type RouterRegister interface {
 	// handlers: 路由级别中间件和处理函数
 	Options(path string, handlers ...Handler) App
 
+	// Any 同时注册 GET, POST, PUT, DELETE, HEAD, PATCH, OPTIONS
+	// path: 路径，以 "/" 开头，不可以为空
+	// handlers: 路由级别中间件和处理函数
+	Any(path string, handlers ...Handler) App
+
+	// Handle 注册任意 Method，包括 CONNECT，TRACE 以及自定义 Method，例如 PROPFIND
+	// method: HTTP Method，MethodAny 与 Any 相同
+	// path: 路径，以 "/" 开头，不可以为空
+	// handlers: 路由级别中间件和处理函数
+	Handle(method, path string, handlers ...Handler) App
+
+	// Match 同时注册多个 Method，Name 和 Meta 作用于所有 Method
+	// 例如: a.Match([]string{MethodGet, MethodPost}, "/login", handler)
+	Match(methods []string, path string, handlers ...Handler) App
+
+	// Name 为最近一次注册的路由命名，用于 Router().URL 生成路径
+	// 例如: a.Get("/blog/:id", handler).Name("blog")
+	Name(name string) App
+
+	// Meta 为最近一次注册的路由添加元数据，匹配成功后通过 ctx.Route().Meta(key) 获取
+	// 例如: a.Get("/admin", handler).Meta("perm", "admin")
+	Meta(key string, value interface{}) App
+
+	// Timeout 为最近一次注册的路由设置超时时间，超时后请求的 context 被取消，并返回 503
+	// 例如: a.Get("/report", handler).Timeout(3 * time.Second)
+	Timeout(timeout time.Duration) App
+
 	// Group 创建组路由实例
 	Group(path string) Group
 
+	// Host 创建域名路由实例，只有请求的域名匹配 pattern 时才会使用其中的路由
+	// pattern 中可以使用 {name} 匹配一级域名，通过 ctx.Dynamic("name") 获取
+	// 例如: a.Host("{tenant}.example.com").Get("/user", handler)
+	Host(pattern string) Group
+
+	// APIVersion 创建 API 版本组路由，其中的路由只有请求的 API 版本匹配时才会使用
+	// 请求的版本来自 Accept-Version 请求头，Accept 中的 application/vnd.{vendor}.v{version}+json，或者查询参数
+	// 例如: a.APIVersion("2").Get("/users", handler)
+	APIVersion(version string) Group
+
+	// When 创建带有匹配条件的组路由，路径匹配之后检查条件，全部满足时才会使用其中的路由
+	// 同一个路由可以注册多次，按照注册顺序检查，都不满足时使用没有匹配条件的路由
+	// 例如: a.When(ContentType("application/x-protobuf")).Post("/rpc", handler)
+	When(predicates ...RoutePredicate) Group
+
 	// Static 添加静态资源服务
 	// prefix 静态资源路由前缀
 	// path 资源真实位置(绝对路径，相对路径)
@@ -129,6 +192,9 @@ type ContextBase interface {
 	// Request 获取原始 http 请求
 	Request() *http.Request
 
+	// SetRequest 替换请求，例如使用 req.WithContext 替换请求的 context
+	SetRequest(req *http.Request)
+
 	// Response 获取 http 响应
 	Response() Writer
 
@@ -138,6 +204,13 @@ type ContextBase interface {
 	// Path 请求路径
 	Path() string
 
+	// Route 获取匹配成功的路由，未匹配时返回 nil
+	// 应用级别中间件执行前已完成匹配，所以中间件中也可以使用
+	Route() MatchedRoute
+
+	// SetRoute 设置匹配成功的路由
+	SetRoute(route MatchedRoute)
+
 	// HTTPCode 获取 http 状态码，默认 200
 	HTTPCode() int
 
@@ -168,6 +241,12 @@ type ContextBase interface {
 	// NotFound 路由未找到，设置 404
 	NotFound()
 
+	// MethodNotAllowed 路由存在，但不支持当前 Method，设置 405
+	MethodNotAllowed()
+
+	// NotAcceptable 请求的 API 版本不存在，设置 406
+	NotAcceptable()
+
 	// IsStopped 判断是否处于停止状态
 	// 比如 auth中间件判断未通过验证，就会调用 Stopped() 来停止继续向下调用
 	IsStopped() bool
@@ -441,11 +520,48 @@ type ContextDynamic interface {
 	// Dynamic("id") -> 1001
 	Dynamic(key string) string
 
+	// DynamicInt 获取动态参数的值，并将结果转为 int
+	DynamicInt(key string) int
+
+	// DynamicInt64 获取动态参数的值，并将结果转为 int64
+	DynamicInt64(key string) int64
+
+	// DynamicUint64 获取动态参数的值，并将结果转为 uint64
+	DynamicUint64(key string) uint64
+
+	// DynamicBool 获取动态参数的值，并将结果转为 bool
+	DynamicBool(key string) bool
+
+	// DynamicFloat64 获取动态参数的值，并将结果转为 float64
+	DynamicFloat64(key string) float64
+
+	// DynamicDefault 获取动态参数的值，如果不存在，则返回默认值 def
+	DynamicDefault(key, def string) string
+
+	// DynamicIntDefault 获取动态参数的值(结果转为 int)，如果不存在或者转换失败，则返回默认值 def
+	DynamicIntDefault(key string, def int) int
+
+	// DynamicInt64Default 获取动态参数的值(结果转为 int64)，如果不存在或者转换失败，则返回默认值 def
+	DynamicInt64Default(key string, def int64) int64
+
+	// DynamicUint64Default 获取动态参数的值(结果转为 uint64)，如果不存在或者转换失败，则返回默认值 def
+	DynamicUint64Default(key string, def uint64) uint64
+
+	// DynamicBoolDefault 获取动态参数的值(结果转为 bool)，如果不存在或者转换失败，则返回默认值 def
+	DynamicBoolDefault(key string, def bool) bool
+
+	// DynamicFloat64Default 获取动态参数的值(结果转为 float64)，如果不存在或者转换失败，则返回默认值 def
+	DynamicFloat64Default(key string, def float64) float64
+
 	// SetDynamic 设置动态参数，key 的格式为 "param" 或者 ":param"
 	SetDynamic(key string, value string) error
 
 	// SetDynamics 替换动态参数
 	SetDynamics(dynamics map[string]string)
+
+	// Params 获取所有动态参数，按照在路径中的顺序排列，可以直接修改
+	// 请求结束后会被复用，需要在请求之外使用时请复制
+	Params() *Params
 }
 
 // ContextFile 文件相关
@@ -607,23 +723,212 @@ type Router interface {
 	// 例如: prefix = "/blog"，则 "/user" -> "/blog/user"
 	Prefix(prefix string)
 
+	// PrefixPath 获取路由前缀，域名路由表返回默认路由表的前缀
+	PrefixPath() string
+
+	// SetMode 设置路由模式，见 RouterModeXxx，多个模式使用 | 组合
+	SetMode(mode int)
+
+	// Mode 获取路由模式
+	Mode() int
+
 	// Register 注册路由处理函数，以及中间件
 	// method: HTTP Method，见 core/const.go Methodxxxx
 	// path: 路径，以 "/" 开头，不可以为空
 	// handles: 处理函数和路由级别中间件，匹配成功后会调用该函数
-	Register(method, path string, handlers ...Handler) bool
+	// 失败时返回 *RouteError，该路由不会被添加，Build 之前的错误会被记录并在 Build 时返回
+	// Build 之后也可以调用，复制路由表添加路由并重新构建，成功后整体替换，不影响正在处理的请求
+	Register(method, path string, handlers ...Handler) error
+
+	// Match 为多个 Method 注册同一个路由，Name 和 Meta 作用于所有注册成功的 Method
+	// 某个 Method 注册失败时不影响其它 Method，返回所有的错误
+	Match(methods []string, path string, handlers ...Handler) error
+
+	// MatchWhen 为多个 Method 注册带有匹配条件的路由，路径匹配之后检查条件，全部满足时才会使用
+	// 同一个路由可以注册多次，按照注册顺序检查，都不满足时使用没有匹配条件的路由，没有时视为未匹配
+	// predicates 为空时与 Match 相同
+	MatchWhen(methods []string, path string, predicates []RoutePredicate, handlers ...Handler) error
+
+	// Remove 删除路由，path 为路由全路径，包括前缀，与 Routes() 中的 Path 相同
+	// method 为 MethodAny 时，删除其对应的所有已注册的 Method，同时删除该路由上所有带有匹配条件的路由
+	// Build 之后也可以调用，与 Register 相同，路由不存在时返回 ErrRouteNotFound
+	Remove(method, path string) error
+
+	// Batch 执行 f，其中的注册，删除路由，设置名称，元数据，超时时间在 f 返回后一次构建并整体替换
+	// Build 之后注册带有元数据的路由时使用，避免路由生效时还没有元数据，f 返回错误或者构建失败时所有修改都不会生效
+	Batch(f func() error) error
+
+	// Name 为最近一次注册的路由命名，名称不可重复，重复时返回 false，并在 Build 时返回 ErrDuplicateName
+	Name(name string) bool
 
-	// Build 解析路由，包括动态参数，正则表达式，验证函数
-	Build() bool
+	// Meta 为最近一次注册的路由添加元数据，同名的 key 会被覆盖
+	Meta(key string, value interface{}) bool
+
+	// Timeout 为最近一次注册的路由设置超时时间，<= 0 时不限制
+	Timeout(timeout time.Duration) bool
+
+	// URL 根据路由名称生成路径，填充动态参数与通配符，并使用正则表达式和验证函数检查参数值
+	// name: 路由名称，见 Name
+	// params: 动态参数，未命名通配符的参数名为 "*"，未提供的可选参数会被省略
+	// query: 查询参数，可以为 nil
+	URL(name string, params map[string]string, query url.Values) (string, error)
+
+	// Build 解析路由，包括动态参数，正则表达式，验证函数，只需要调用一次
+	// 失败时返回所有的错误，包括注册路由时的错误，每一个都是 *RouteError，可以使用 errors.Is(err, ErrXxx) 判断类型
+	Build() error
 
 	// Lookup 查找路由
 	Lookup(method, path string) ([]Handler, map[string]string)
 
+	// LookupRoute 查找路由，返回匹配成功的路由，未匹配时返回 nil
+	// 不检查匹配条件，只返回没有匹配条件的路由，见 LookupRouteFor
+	LookupRoute(method, path string) (MatchedRoute, map[string]string)
+
+	// LookupRouteFor 查找路由，并使用请求检查匹配条件，返回匹配成功的路由，未匹配时返回 nil
+	LookupRouteFor(req *http.Request, method, path string) (MatchedRoute, map[string]string)
+
+	// LookupRouteParams 与 LookupRouteFor 相同，动态参数依次添加到 params 末尾，req 为 nil 时不检查匹配条件
+	// 未匹配时 params 保持不变，Build 之后匹配路由不需要分配内存
+	LookupRouteParams(req *http.Request, method, path string, params *Params) MatchedRoute
+
+	// Routes 获取所有已注册的路由，按照域名，路径，Method 排序
+	// 默认路由表同时包含所有域名路由表中的路由
+	Routes() []RouteInfo
+
+	// Dump 打印每一种 Method 的基数树结构，建议在 Build 之后调用
+	Dump(w io.Writer)
+
+	// Allowed 获取能够匹配 path 的所有 Method
+	Allowed(path string) []string
+
+	// FixPath 根据路由模式修正 path，返回能够匹配的规范路径，无法修正时返回 ""
+	// 见 RouterModeRedirectTrailingSlash，RouterModeRedirectFixedPath
+	FixPath(method, path string) string
+
 	// RegisterRouterValidator 注册路由验证函数
 	RegisterRouterValidator(name string, validator RouterValidator)
 
-	// Validator 获取路由验证函数
+	// Validator 获取路由验证函数，包括框架自带的验证函数
 	Validator(name string) RouterValidator
+
+	// Validators 获取所有可用的验证函数名称，包括框架自带的验证函数，按字母序排列
+	// 带参数的验证函数名称以 "()" 结尾，例如 "len()"
+	Validators() []string
+
+	// RegisterRouterValidatorFactory 注册带参数的验证函数的生成函数
+	// 例如注册 len 后，可以在路由中使用 |len(3,16)|
+	RegisterRouterValidatorFactory(name string, factory RouterValidatorFactory)
+
+	// ValidatorFactory 获取带参数的验证函数的生成函数，包括框架自带的生成函数
+	ValidatorFactory(name string) RouterValidatorFactory
+
+	// Host 获取域名对应的路由表，不存在时创建，pattern 为空时返回默认路由表
+	// 域名路由表与默认路由表共享前缀，路由模式，验证函数
+	// 例如: {tenant}.example.com 匹配 yaha.example.com，tenant="yaha"
+	Host(pattern string) Router
+
+	// HostPattern 获取路由表对应的域名，默认路由表返回 ""
+	HostPattern() string
+
+	// MatchHost 根据请求的域名选择路由表，同时返回域名中的参数，都不匹配时返回默认路由表
+	MatchHost(host string) (Router, map[string]string)
+
+	// APIVersion 获取 API 版本对应的路由表，不存在时创建，version 为空时返回当前路由表
+	// 版本号忽略开头的 "v"，例如 "v2" 与 "2" 相同
+	APIVersion(version string) Router
+
+	// APIVersionName 获取路由表对应的 API 版本，不是版本路由表时返回 ""
+	APIVersionName() string
+
+	// MatchAPIVersion 获取 API 版本对应的路由表，不存在时返回 nil
+	MatchAPIVersion(version string) Router
+}
+
+// RouteInfo 路由信息
+type RouteInfo struct {
+	// Host 域名，默认路由表为 ""
+	Host string
+
+	// Version API 版本，不是版本路由表中的路由时为 ""
+	Version string
+
+	// Method HTTP Method
+	Method string
+
+	// Path 路由全路径，例如 /blog/:id(^\d+$)|less4|
+	Path string
+
+	// Name 路由名称，见 Router.Name
+	Name string
+
+	// Params 动态参数与通配符，按照在路径中的顺序排列
+	Params []RouteParamInfo
+
+	// HandlerCount 路由处理函数和路由级别中间件的数量
+	HandlerCount int
+
+	// HandlerNames 路由处理函数和路由级别中间件的函数名称
+	HandlerNames []string
+
+	// Predicates 匹配条件的数量，没有匹配条件时为 0，见 App.When
+	Predicates int
+
+	// Timeout 超时时间，未设置时为 0，见 Router.Timeout
+	Timeout time.Duration
+
+	// Meta 路由元数据，见 Router.Meta
+	Meta map[string]interface{}
+}
+
+// MatchedRoute 匹配成功的路由
+type MatchedRoute interface {
+	// Method 注册路由时使用的 HTTP Method
+	Method() string
+
+	// Host 域名，默认路由表为 ""
+	Host() string
+
+	// APIVersion API 版本，不是版本路由表中的路由时为 ""
+	APIVersion() string
+
+	// Path 路由全路径，例如 /blog/:id(^\d+$)
+	Path() string
+
+	// Name 路由名称，见 Router.Name
+	Name() string
+
+	// Handlers 路由处理函数和路由级别中间件
+	Handlers() []Handler
+
+	// Meta 获取元数据，不存在时返回 nil
+	Meta(key string) interface{}
+
+	// Metas 获取所有元数据，不可以修改
+	Metas() map[string]interface{}
+
+	// Timeout 超时时间，未设置时为 0
+	Timeout() time.Duration
+}
+
+// RouteParamInfo 动态参数信息
+type RouteParamInfo struct {
+	// Name 参数名称，未命名通配符为 "*"
+	Name string
+
+	// Regexp 正则表达式
+	Regexp string
+
+	// Captures 正则表达式中命名分组的名称，匹配成功后同样作为动态参数
+	Captures []string
+
+	// Validators 验证函数名称
+	Validators []string
+
+	// Optional 是否为可选参数
+	Optional bool
+
+	// Default 可选参数的默认值
+	Default string
 }
 
 // Group 组路由，相同前缀的一组路由，共享相同的中间件
@@ -631,6 +936,24 @@ type Group interface {
 	// Use 添加 Group 级别 中间件
 	Use(handlers ...Handler) Group
 
+	// Group 创建下级组路由，继承当前组路由的前缀和中间件，中间件按照从上级到下级的顺序执行
+	// 例如: a.Group("/api").Group("/v1").Group("/admin") 中的路由前缀为 /api/v1/admin
+	Group(path string) Group
+
+	// Prefix 获取实际的路由前缀，包括 App.Prefix 设置的前缀以及所有上级组路由的前缀
+	Prefix() string
+
+	// APIVersion 创建 API 版本组路由，继承当前组路由的前缀和中间件，路由注册到版本路由表中
+	APIVersion(version string) Group
+
+	// When 创建带有匹配条件的下级组路由，继承当前组路由的前缀，中间件和匹配条件
+	When(predicates ...RoutePredicate) Group
+
+	// Static 添加静态资源服务
+	// prefix 静态资源路由前缀，会添加组路由的前缀
+	// path 资源真实位置(绝对路径，相对路径)
+	Static(prefix, path string) Group
+
 	// Get method = "GET"
 	Get(path string, handlers ...Handler) Group
 
@@ -651,6 +974,28 @@ type Group interface {
 
 	// Options method = "OPTIONS"
 	Options(path string, handlers ...Handler) Group
+
+	// Any 同时注册 GET, POST, PUT, DELETE, HEAD, PATCH, OPTIONS
+	Any(path string, handlers ...Handler) Group
+
+	// Handle 注册任意 Method，包括 CONNECT，TRACE 以及自定义 Method
+	Handle(method, path string, handlers ...Handler) Group
+
+	// Match 同时注册多个 Method，Name 和 Meta 作用于所有 Method
+	Match(methods []string, path string, handlers ...Handler) Group
+
+	// Name 为最近一次注册的路由命名
+	Name(name string) Group
+
+	// Meta 为最近一次注册的路由添加元数据
+	Meta(key string, value interface{}) Group
+
+	// Timeout 为最近一次注册的路由设置超时时间
+	Timeout(timeout time.Duration) Group
+
+	// UseTimeout 设置组路由级别超时时间，与 Use 相同，只作用于之后注册的路由，包括下级组路由
+	// 路由自身的 Timeout 优先
+	UseTimeout(timeout time.Duration) Group
 }
 
 // RouteNode 一颗基数树的一个节点
@@ -659,14 +1004,25 @@ type RouteNode interface {
 	Put(fullPath string, paths []string, height int, handlers ...Handler)
 
 	// Build 解析路由，包括动态参数，正则表达式，验证函数。路由优化
-	Build(router Router) bool
+	// 失败时返回 *RouteError
+	Build(router Router) error
 
 	// Lookup 查找路由
 	Lookup(path string, dynamic map[string]string) ([]Handler, map[string]string)
 
+	// LookupNode 查找路由，返回含有路由处理函数的节点
+	LookupNode(path string, dynamic map[string]string) (RouteNode, map[string]string)
+
+	// LookupParams 查找路由，返回含有路由处理函数的节点，动态参数依次添加到 params 末尾
+	// 未匹配时 params 保持不变，params 可以复用，不需要分配内存
+	LookupParams(path string, params *Params) RouteNode
+
 	// Path 获取当前节点路径
 	Path() string
 
+	// FullPath 获取路由全路径，只有含有路由处理函数的节点才有值
+	FullPath() string
+
 	// Child 查找节点信息
 	Child(path string) RouteNode
 
@@ -700,6 +1056,12 @@ type RouteNodeInternal interface {
 	// IsValidator 含有验证函数
 	IsValidator() bool
 
+	// IsMultiple 一个节点中含有多个动态参数，或者动态参数与字面量混合
+	IsMultiple() bool
+
+	// IsOptional 可选的动态参数
+	IsOptional() bool
+
 	// IsHandler 是否有路由处理函数或者中间件
 	IsHandler() bool
 
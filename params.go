@@ -0,0 +1,48 @@
+package zeroapi
+
+// Param 一个动态参数
+type Param struct {
+	Key   string
+	Value string
+}
+
+// Params 动态参数，按照在路径中的顺序排列
+// 由 Context 持有并在请求之间复用，匹配路由时直接填充，不需要为每个请求分配内存
+type Params []Param
+
+// Get 获取动态参数的值，同名时以后面的为准
+func (ps Params) Get(key string) (string, bool) {
+	for i := len(ps) - 1; i >= 0; i-- {
+		if ps[i].Key == key {
+			return ps[i].Value, true
+		}
+	}
+
+	return "", false
+}
+
+// Set 设置动态参数的值，已存在时覆盖，否则添加到最后
+func (ps *Params) Set(key, value string) {
+	for i := len(*ps) - 1; i >= 0; i-- {
+		if (*ps)[i].Key == key {
+			(*ps)[i].Value = value
+			return
+		}
+	}
+
+	*ps = append(*ps, Param{Key: key, Value: value})
+}
+
+// Map 转为 map，没有动态参数时返回 nil
+func (ps Params) Map() map[string]string {
+	if len(ps) == 0 {
+		return nil
+	}
+
+	m := make(map[string]string, len(ps))
+	for _, p := range ps {
+		m[p.Key] = p.Value
+	}
+
+	return m
+}
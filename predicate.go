@@ -0,0 +1,170 @@
+package zeroapi
+
+import (
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// RoutePredicate 路由匹配条件，路径匹配之后检查，见 App.When
+// 也可以直接使用自定义函数，例如 func(req *http.Request) bool { return req.ContentLength > 0 }
+type RoutePredicate func(req *http.Request) bool
+
+// Headers 请求头匹配，参数为 key, value 成对出现，value 为空时只要求请求头存在
+// 例如: Headers("X-Requested-With", "XMLHttpRequest")
+func Headers(pairs ...string) RoutePredicate {
+	return func(req *http.Request) bool {
+		for i := 0; i < len(pairs); i += 2 {
+			values, exist := req.Header[http.CanonicalHeaderKey(pairs[i])]
+			if !exist || !matchValue(values, pairs, i) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// Queries 查询参数匹配，参数为 key, value 成对出现，value 为空时只要求查询参数存在
+// 例如: Queries("format", "csv")
+func Queries(pairs ...string) RoutePredicate {
+	return func(req *http.Request) bool {
+		query := req.URL.Query()
+
+		for i := 0; i < len(pairs); i += 2 {
+			values, exist := query[pairs[i]]
+			if !exist || !matchValue(values, pairs, i) {
+				return false
+			}
+		}
+
+		return true
+	}
+}
+
+// matchValue pairs[i+1] 为空，或者等于其中一个值
+func matchValue(values []string, pairs []string, i int) bool {
+	if i+1 >= len(pairs) || pairs[i+1] == "" {
+		return true
+	}
+
+	for _, value := range values {
+		if value == pairs[i+1] {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ContentType Content-Type 等于其中一个，不区分大小写，忽略 charset 等参数
+// 例如: ContentType("application/json", "application/x-protobuf")
+func ContentType(types ...string) RoutePredicate {
+	return func(req *http.Request) bool {
+		mediaType, _, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+		if err != nil {
+			return false
+		}
+
+		for _, t := range types {
+			if strings.EqualFold(mediaType, t) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Scheme 请求协议等于其中一个，例如 Scheme("https")，根据是否使用 TLS 判断
+// 不使用 X-Forwarded-Proto 请求头，客户端可以任意设置，使用反向代理时见 ForwardedScheme
+func Scheme(schemes ...string) RoutePredicate {
+	return func(req *http.Request) bool {
+		return matchScheme(requestScheme(req), schemes)
+	}
+}
+
+// ForwardedScheme 与 Scheme 相同，请求来自 proxies 中的反向代理时，优先使用 X-Forwarded-Proto 请求头
+// proxies 为 IP 或者 CIDR，例如 []string{"10.0.0.0/8", "127.0.0.1"}，格式错误时 panic
+// 例如: ForwardedScheme([]string{"10.0.0.0/8"}, "https")
+func ForwardedScheme(proxies []string, schemes ...string) RoutePredicate {
+	prefixes := make([]netip.Prefix, 0, len(proxies))
+	for _, proxy := range proxies {
+		prefix, err := parseProxy(proxy)
+		if err != nil {
+			panic(fmt.Sprintf("invalid trusted proxy \"%s\": %s", proxy, err.Error()))
+		}
+		prefixes = append(prefixes, prefix)
+	}
+
+	return func(req *http.Request) bool {
+		scheme := requestScheme(req)
+		if forwarded := req.Header.Get("X-Forwarded-Proto"); forwarded != "" && isTrustedProxy(req.RemoteAddr, prefixes) {
+			scheme = forwarded
+		}
+
+		return matchScheme(scheme, schemes)
+	}
+}
+
+// requestScheme 根据是否使用 TLS 判断请求协议
+func requestScheme(req *http.Request) string {
+	if req.TLS != nil {
+		return "https"
+	}
+
+	return "http"
+}
+
+// matchScheme scheme 是否等于其中一个，不区分大小写
+func matchScheme(scheme string, schemes []string) bool {
+	for _, s := range schemes {
+		if strings.EqualFold(scheme, s) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parseProxy 解析 IP 或者 CIDR
+func parseProxy(proxy string) (netip.Prefix, error) {
+	if strings.IndexByte(proxy, '/') != -1 {
+		prefix, err := netip.ParsePrefix(proxy)
+		return prefix.Masked(), err
+	}
+
+	addr, err := netip.ParseAddr(proxy)
+	if err != nil {
+		return netip.Prefix{}, err
+	}
+
+	addr = addr.Unmap()
+
+	return netip.PrefixFrom(addr, addr.BitLen()), nil
+}
+
+// isTrustedProxy remoteAddr 是否在 prefixes 中，remoteAddr 为 http.Request.RemoteAddr，例如 10.0.0.1:52000
+func isTrustedProxy(remoteAddr string, prefixes []netip.Prefix) bool {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+
+	addr, err := netip.ParseAddr(host)
+	if err != nil {
+		return false
+	}
+
+	addr = addr.Unmap()
+	for _, prefix := range prefixes {
+		if prefix.Contains(addr) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,105 @@
+package router_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+
+	zerologger "github.com/zerogo-hub/zero-helper/logger"
+)
+
+// warnLogger 记录警告日志
+type warnLogger struct {
+	zerologger.Logger
+
+	warnings []string
+}
+
+func (l *warnLogger) Warnf(format string, v ...interface{}) {
+	l.warnings = append(l.warnings, fmt.Sprintf(format, v...))
+}
+
+func TestRouterCaptures(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+
+	r.Register(zeroapi.MethodGet, `/archive/:date(^(?P<year>\d{4})-(?P<month>\d{2})$)`, emptyHandle)
+	r.Register(zeroapi.MethodGet, `/report/:year(^(?P<y>\d{4})$)-:month(^\d{2}$)`, emptyHandle)
+	r.Register(zeroapi.MethodGet, `/files/*filepath((?P<ext>\.\w+)$)`, emptyHandle)
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		expected map[string]string
+	}{
+		{"/archive/2024-05", map[string]string{"date": "2024-05", "year": "2024", "month": "05"}},
+		{"/report/2024-05", map[string]string{"year": "2024", "y": "2024", "month": "05"}},
+		{"/files/docs/readme.md", map[string]string{"filepath": "docs/readme.md", "ext": ".md"}},
+	}
+	for _, test := range tests {
+		_, dynamic := r.Lookup(zeroapi.MethodGet, test.path)
+		if len(dynamic) != len(test.expected) {
+			t.Fatalf("%s: invalid params: %v", test.path, dynamic)
+		}
+		for key, value := range test.expected {
+			if dynamic[key] != value {
+				t.Fatalf("%s: invalid params: %v", test.path, dynamic)
+			}
+		}
+	}
+
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/archive/2024-5"); handlers != nil {
+		t.Fatal("regexp should not match")
+	}
+
+	// 命名分组同样出现在路由信息中
+	for _, info := range r.Routes() {
+		if info.Path == "/archive/:date(^(?P<year>\\d{4})-(?P<month>\\d{2})$)" {
+			if captures := info.Params[0].Captures; len(captures) != 2 || captures[0] != "year" || captures[1] != "month" {
+				t.Fatalf("invalid captures: %v", captures)
+			}
+		}
+	}
+}
+
+func TestRouterUnanchoredWarning(t *testing.T) {
+	logger := &warnLogger{Logger: zerologger.NewSampleLogger()}
+	a := zeroapp.NewApp(zeroapp.WithLogger(logger))
+
+	a.Get(`/a/:id(^\d+$)`, emptyHandle)
+	a.Get(`/b/:id(^[a-z]+$|^\d+$)`, emptyHandle)
+	a.Get(`/c/:id((^\d+$))`, emptyHandle)
+	a.Get(`/d/*filepath(\.md$)`, emptyHandle)
+	if len(logger.warnings) != 0 {
+		t.Fatalf("anchored regexp should not warn: %v", logger.warnings)
+	}
+
+	a.Get(`/e/:id(\d+)`, emptyHandle)
+	a.Get(`/f/:id(^\d+)`, emptyHandle)
+	a.Get(`/g/:id(^[a-z]+$|\d+)`, emptyHandle)
+	a.Get(`/h/v:major(\d+).:minor(^\d+$)`, emptyHandle)
+	if len(logger.warnings) != 4 {
+		t.Fatalf("unanchored regexp should warn: %v", logger.warnings)
+	}
+	if w := logger.warnings[0]; !strings.Contains(w, `GET /e/:id(\d+)`) || !strings.Contains(w, `param "id"`) {
+		t.Fatalf("warning should name the route and param: %s", w)
+	}
+	if w := logger.warnings[3]; !strings.Contains(w, `param "major"`) {
+		t.Fatalf("warning should name the param: %s", w)
+	}
+
+	// 只输出警告，不会自动锚定
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+	handlers, dynamic := a.Router().Lookup(zeroapi.MethodGet, "/e/12abc")
+	if handlers == nil || dynamic["id"] != "12abc" {
+		t.Fatalf("unanchored regexp should match substring: %v", dynamic)
+	}
+}
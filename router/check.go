@@ -0,0 +1,263 @@
+package router
+
+import (
+	"errors"
+	"fmt"
+	"regexp/syntax"
+	"strings"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// checkPath 注册前检查路由，t 为未 Build 的路由表，失败时返回 *zeroapi.RouteError
+// 严格模式下同时检查验证函数是否已注册，否则在 Build 时检查
+// conditional 为 true 时表示带有匹配条件的路由，不检查是否重复
+func (r *router) checkPath(t *routeTable, method, path string, conditional bool) error {
+	newError := func(segment string, err error) error {
+		return &zeroapi.RouteError{Method: method, Host: r.host, Path: path, Segment: segment, Err: err}
+	}
+
+	if !conditional && r.registered[metaKey(method, path)] {
+		return newError("", zeroapi.ErrDuplicateRoute)
+	}
+
+	// 通配符只能是最后一个片段
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if segment != "" && segment[0] == WildcardCharacter && i != len(segments)-1 {
+			return newError("/"+segment, zeroapi.ErrWildcardNotLast)
+		}
+	}
+
+	var router zeroapi.Router
+	if r.Mode()&zeroapi.RouterModeStrict != 0 {
+		router = r
+	}
+
+	paths := buildPath(path)
+
+	optional := false
+	for _, segment := range paths {
+		segment, isOptional, _ := parseOptional(segment)
+
+		node := newChild(segment).(*routeNode)
+		if isOptional {
+			node.flag |= OPTIONAL
+			optional = true
+		} else if optional {
+			return newError(segment, fmt.Errorf("%w: required segment after optional param", zeroapi.ErrInvalidSegment))
+		}
+
+		if node.IsStatic() {
+			continue
+		}
+
+		if err := node.Build(router); err != nil {
+			var e *zeroapi.RouteError
+			if errors.As(err, &e) {
+				return newError(e.Segment, e.Err)
+			}
+			return newError(segment, err)
+		}
+	}
+
+	if re := t.routes[method]; re != nil {
+		if segment, existing := conflict(re.Root(), paths); segment != "" {
+			return newError(segment, fmt.Errorf("%w: \"%s\" conflicts with \"%s\"", zeroapi.ErrParamConflict, segment, existing))
+		}
+
+		// 带有匹配条件的路由可以与没有匹配条件的路由重叠
+		if !conditional {
+			if existing := overlap(re.Root().(*routeNode), paths); existing != "" {
+				return newError("", fmt.Errorf("%w: overlaps with \"%s\"", zeroapi.ErrDuplicateRoute, existing))
+			}
+		}
+	}
+
+	return nil
+}
+
+// conflict 查找与 paths 在同一位置，只有参数名称不同的已注册节点
+// 例如已注册 /user/:id，则 /user/:name 冲突，返回 "/:name", "/:id"
+func conflict(node zeroapi.RouteNode, paths []string) (string, string) {
+	for _, path := range paths {
+		path, _, _ = parseOptional(path)
+
+		path = segmentPath(path)
+
+		var next zeroapi.RouteNode
+		for _, child := range node.Children() {
+			if child.Path() == path {
+				next = child
+				break
+			}
+		}
+
+		if next == nil {
+			signature := paramSignature(path)
+			for _, child := range node.Children() {
+				if !child.IsStatic() && paramSignature(child.Path()) == signature {
+					return path, child.Path()
+				}
+			}
+
+			return "", ""
+		}
+
+		node = next
+	}
+
+	return "", ""
+}
+
+// overlap 查找与 paths 匹配相同请求的已注册路由，返回其全路径，没有时返回 ""
+// 按照解析后的路径片段比较，例如 /user/ 与 /user，//user 相同
+// 可选参数省略时与上级路径匹配相同的请求，例如 /a/:b? 与 /a，/a/:b 都重叠
+func overlap(root *routeNode, paths []string) string {
+	// claimed 新路由能够匹配的节点
+	var claimed []*routeNode
+
+	node := root
+	for _, path := range paths {
+		path, isOptional, _ := parseOptional(path)
+		if isOptional {
+			claimed = append(claimed, node)
+		}
+
+		child, _ := node.child(segmentPath(path)).(*routeNode)
+		if child == nil {
+			// 之后的节点都不存在
+			node = nil
+			break
+		}
+
+		node = child
+	}
+
+	if node != nil {
+		claimed = append(claimed, node)
+	}
+
+	for _, node := range claimed {
+		if owner := handlerOwner(node); owner != nil {
+			return owner.fullPath
+		}
+	}
+
+	return ""
+}
+
+// handlerOwner 匹配 node 时使用的节点，node 没有处理函数时依次查找可选参数子节点，见 lookupOptional
+func handlerOwner(node *routeNode) *routeNode {
+	if len(node.handlers) > 0 {
+		return node
+	}
+
+	for _, child := range node.children {
+		if child, ok := child.(*routeNode); ok && child.IsOptional() {
+			if owner := handlerOwner(child); owner != nil {
+				return owner
+			}
+		}
+	}
+
+	return nil
+}
+
+// paramSignature 去除参数名称后的路径片段，用于判断两个片段是否只有参数名称不同
+// 例如: /:id(^\d+$) -> /:(^\d+$)，/*filepath+ -> /*+
+func paramSignature(path string) string {
+	node := newChild(path).(*routeNode)
+
+	if node.IsWildcard() {
+		return "/*" + path[node.nameEnd():]
+	}
+
+	if !node.IsDynamic() {
+		return path
+	}
+
+	tokens, err := splitSegment(path[1:])
+	if err != nil {
+		return path
+	}
+
+	var b strings.Builder
+	b.WriteByte('/')
+
+	for _, token := range tokens {
+		if token[0] != DynamicCharacter {
+			b.WriteString(token)
+			continue
+		}
+
+		n := 1
+		for n < len(token) && isNameChar(token[n]) {
+			n++
+		}
+
+		b.WriteByte(DynamicCharacter)
+		b.WriteString(token[n:])
+	}
+
+	return b.String()
+}
+
+// warnUnanchored 动态参数的正则表达式没有同时以 ^ 开头，以 $ 结尾时输出警告，警告中包含 Method，路由，域名和参数名称
+// 不会自动锚定，未锚定的正则表达式只要求包含匹配的内容，例如 /:id(\d+) 可以匹配 /12abc
+// 通配符的正则表达式通常用于匹配后缀，例如 /*filepath(\.md$)，不检查
+func (r *router) warnUnanchored(methods []string, path string) {
+	app := r.root().app
+	if app == nil || app.Logger() == nil {
+		return
+	}
+
+	route := strings.Join(methods, ",") + " " + path
+	if r.host != "" {
+		route += " host=" + r.host
+	}
+
+	for _, segment := range buildPath(path) {
+		if len(segment) > 1 && segment[1] == WildcardCharacter {
+			break
+		}
+
+		for _, info := range paramInfos(segment) {
+			if info.Regexp != "" && !isAnchored(info.Regexp) {
+				app.Logger().Warnf("route \"%s\": regexp \"%s\" of param \"%s\" is not anchored and also matches substrings, use ^...$ to match the whole value", route, info.Regexp, info.Name)
+			}
+		}
+	}
+}
+
+// isAnchored 正则表达式是否同时锚定开头与结尾，多个分支时每个分支都需要锚定
+func isAnchored(expr string) bool {
+	re, err := syntax.Parse(expr, syntax.Perl)
+	if err != nil {
+		// 注册时已检查
+		return true
+	}
+
+	return anchored(re)
+}
+
+// anchored 见 isAnchored
+func anchored(re *syntax.Regexp) bool {
+	switch re.Op {
+	case syntax.OpCapture:
+		return anchored(re.Sub[0])
+	case syntax.OpAlternate:
+		for _, sub := range re.Sub {
+			if !anchored(sub) {
+				return false
+			}
+		}
+		return true
+	case syntax.OpConcat:
+		first, last := re.Sub[0], re.Sub[len(re.Sub)-1]
+		return (first.Op == syntax.OpBeginText || first.Op == syntax.OpBeginLine) &&
+			(last.Op == syntax.OpEndText || last.Op == syntax.OpEndLine)
+	}
+
+	return false
+}
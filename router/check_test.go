@@ -0,0 +1,148 @@
+package router_test
+
+import (
+	"errors"
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func TestRouterBuildErrors(t *testing.T) {
+	tests := []struct {
+		paths   []string
+		err     error
+		path    string
+		segment string
+	}{
+		{[]string{"/user/:id(\\d+[)"}, zeroapi.ErrInvalidRegexp, "/user/:id(\\d+[)", "/:id(\\d+[)"},
+		{[]string{"/user/:id()"}, zeroapi.ErrInvalidRegexp, "/user/:id()", "/:id()"},
+		{[]string{"/user/:id|isNun|"}, zeroapi.ErrValidatorNotFound, "/user/:id|isNun|", "/:id|isNun|"},
+		{[]string{"/user/:id|len(a)|"}, zeroapi.ErrInvalidValidator, "/user/:id|len(a)|", "/:id|len(a)|"},
+		{[]string{"/user/:id(\\d+"}, zeroapi.ErrUnbalanced, "/user/:id(\\d+", "/:id(\\d+"},
+		{[]string{"/user/:id|isNum"}, zeroapi.ErrUnbalanced, "/user/:id|isNum", "/:id|isNum"},
+		{[]string{"/user/:id)"}, zeroapi.ErrUnbalanced, "/user/:id)", "/:id)"},
+		{[]string{"/user/:id", "/user/:id"}, zeroapi.ErrDuplicateRoute, "/user/:id", ""},
+		{[]string{"/user", "/user/"}, zeroapi.ErrDuplicateRoute, "/user/", ""},
+		{[]string{"/user/:id/", "/user//:id"}, zeroapi.ErrDuplicateRoute, "/user//:id", ""},
+		{[]string{"/files/*path/info"}, zeroapi.ErrWildcardNotLast, "/files/*path/info", "/*path"},
+		{[]string{"/user/:id", "/user/:name"}, zeroapi.ErrParamConflict, "/user/:name", "/:name"},
+		{[]string{"/user/:id(^\\d+$)/a", "/user/:uid(^\\d+$)/b"}, zeroapi.ErrParamConflict, "/user/:uid(^\\d+$)/b", "/:uid(^\\d+$)"},
+		{[]string{"/user/:"}, zeroapi.ErrInvalidSegment, "/user/:", "/:"},
+		{[]string{"/list/:page?/:size"}, zeroapi.ErrInvalidSegment, "/list/:page?/:size", "/:size"},
+		{[]string{"/list/:page", "/list/:page?"}, zeroapi.ErrDuplicateRoute, "/list/:page?", ""},
+		{[]string{"/list/:page?", "/list/:page"}, zeroapi.ErrDuplicateRoute, "/list/:page", ""},
+		{[]string{"/list/:page?=1", "/list"}, zeroapi.ErrDuplicateRoute, "/list", ""},
+		{[]string{"/list", "/list/:page?/:size?"}, zeroapi.ErrDuplicateRoute, "/list/:page?/:size?", ""},
+	}
+
+	for _, test := range tests {
+		r := zeroapp.NewApp().Router()
+		for _, path := range test.paths {
+			r.Register(zeroapi.MethodGet, path, emptyHandle)
+		}
+
+		err := r.Build()
+		if !errors.Is(err, test.err) {
+			t.Fatalf("%v: invalid error: %v", test.paths, err)
+		}
+
+		var e *zeroapi.RouteError
+		if !errors.As(err, &e) {
+			t.Fatalf("%v: not a route error: %v", test.paths, err)
+		}
+		if e.Method != zeroapi.MethodGet || e.Path != test.path || e.Segment != test.segment {
+			t.Fatalf("%v: invalid route error: %+v", test.paths, e)
+		}
+	}
+}
+
+func TestRouterRegisterError(t *testing.T) {
+	r := zeroapp.NewApp().Router()
+
+	if err := r.Register(zeroapi.MethodGet, "/user/:id(\\d+", emptyHandle); !errors.Is(err, zeroapi.ErrUnbalanced) {
+		t.Fatalf("invalid error: %v", err)
+	}
+
+	// 未注册的验证函数在 Build 时检查
+	if err := r.Register(zeroapi.MethodGet, "/blog/:id|isNun|", emptyHandle); err != nil {
+		t.Fatal(err)
+	}
+
+	// 允许只有正则表达式不同的动态参数
+	if err := r.Register(zeroapi.MethodGet, "/item/:id(^\\d+$)", emptyHandle); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Register(zeroapi.MethodGet, "/item/:name", emptyHandle); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不同 Method 不冲突
+	if err := r.Register(zeroapi.MethodPost, "/item/:slug", emptyHandle); err != nil {
+		t.Fatal(err)
+	}
+
+	// 所有错误都会在 Build 时返回
+	err := r.Build()
+	if !errors.Is(err, zeroapi.ErrUnbalanced) || !errors.Is(err, zeroapi.ErrValidatorNotFound) {
+		t.Fatalf("invalid error: %v", err)
+	}
+}
+
+func TestRouterStrict(t *testing.T) {
+	a := zeroapp.NewApp(zeroapp.WithStrictRouting(true))
+
+	if err := a.Router().Register(zeroapi.MethodGet, "/blog/:id|isNun|", emptyHandle); !errors.Is(err, zeroapi.ErrValidatorNotFound) {
+		t.Fatalf("invalid error: %v", err)
+	}
+
+	defer func() {
+		p := recover()
+
+		err, ok := p.(error)
+		if !ok || !errors.Is(err, zeroapi.ErrDuplicateRoute) {
+			t.Fatalf("invalid panic: %v", p)
+		}
+	}()
+
+	a.Get("/user", emptyHandle)
+	a.Get("/user", emptyHandle)
+}
+
+func TestRouterOptionalTrailingSlash(t *testing.T) {
+	optional := zeroapp.NewApp().Router()
+	optional.SetMode(optional.Mode() | zeroapi.RouterModeRedirectTrailingSlash)
+	optional.Register(zeroapi.MethodGet, "/list/:page?", emptyHandle)
+
+	plain := zeroapp.NewApp().Router()
+	plain.SetMode(plain.Mode() | zeroapi.RouterModeRedirectTrailingSlash)
+	plain.Register(zeroapi.MethodGet, "/list", emptyHandle)
+	plain.Register(zeroapi.MethodGet, "/list/:page", emptyHandle)
+
+	// 不同的注册方法可以同时使用
+	optional.Register(zeroapi.MethodPost, "/list", emptyHandle)
+
+	if err := optional.Build(); err != nil {
+		t.Fatal(err)
+	}
+	if err := plain.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 可选参数与分别注册的路由，末尾 "/" 的处理相同
+	for _, path := range []string{"/list", "/list/", "/list/2", "/list/2/"} {
+		h1, d1 := optional.Lookup(zeroapi.MethodGet, path)
+		h2, d2 := plain.Lookup(zeroapi.MethodGet, path)
+		if (h1 == nil) != (h2 == nil) || d1["page"] != d2["page"] {
+			t.Fatalf("%s: lookup differs: %v %v", path, d1, d2)
+		}
+
+		if f1, f2 := optional.FixPath(zeroapi.MethodGet, path), plain.FixPath(zeroapi.MethodGet, path); f1 != f2 {
+			t.Fatalf("%s: fix path differs: %s %s", path, f1, f2)
+		}
+
+		if a1, a2 := optional.Allowed(path), plain.Allowed(path); (len(a1) == 0) != (len(a2) == 0) {
+			t.Fatalf("%s: allowed differs: %v %v", path, a1, a2)
+		}
+	}
+}
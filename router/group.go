@@ -1,6 +1,12 @@
 package router
 
 import (
+	"net/url"
+	_path "path"
+	"path/filepath"
+	"strings"
+	"time"
+
 	zeroapi "github.com/zerogo-hub/zero-api"
 )
 
@@ -8,17 +14,86 @@ type group struct {
 	app    zeroapi.App
 	prefix string
 
+	// router 路由注册到该路由表中，默认为 app.Router()
+	router zeroapi.Router
+
+	// parent 上级组路由，注册路由时依次添加上级组路由的前缀和中间件
+	parent *group
+
 	// middlewares 组路由级别中间件
 	middlewares []zeroapi.Handler
+
+	// predicates 匹配条件，注册路由时依次添加上级组路由的匹配条件
+	predicates []zeroapi.RoutePredicate
+
+	// timeout 组路由级别超时时间，为 0 时使用上级组路由的超时时间
+	timeout time.Duration
 }
 
 // NewGroup 创建一个组路由示例
 func NewGroup(app zeroapi.App, prefix string) zeroapi.Group {
+	return &group{app: app, prefix: groupPrefix(prefix), router: app.Router()}
+}
+
+// NewHostGroup 创建一个域名路由实例，路由注册到域名对应的路由表中
+func NewHostGroup(app zeroapi.App, host string) zeroapi.Group {
+	return &group{app: app, router: app.Router().Host(host)}
+}
+
+// NewVersionGroup 创建一个 API 版本组路由实例，路由注册到版本对应的路由表中
+func NewVersionGroup(app zeroapi.App, version string) zeroapi.Group {
+	return &group{app: app, router: app.Router().APIVersion(version)}
+}
+
+// groupPrefix 补全开头的 "/"，去除末尾的 "/"
+func groupPrefix(prefix string) string {
 	if prefix != "" && prefix[0] != '/' {
 		prefix = "/" + prefix
 	}
 
-	return &group{app: app, prefix: prefix}
+	return strings.TrimRight(prefix, "/")
+}
+
+// Group 创建下级组路由，继承当前组路由的前缀和中间件
+// 例如: a.Group("/api").Group("/v1").Group("/admin") 中的路由前缀为 /api/v1/admin
+func (g *group) Group(path string) zeroapi.Group {
+	return &group{app: g.app, prefix: groupPrefix(path), router: g.router, parent: g}
+}
+
+// Prefix 获取实际的路由前缀，包括路由表的前缀以及所有上级组路由的前缀
+func (g *group) Prefix() string {
+	return g.router.PrefixPath() + g.fullPrefix()
+}
+
+// fullPrefix 所有上级组路由的前缀 + 当前组路由的前缀
+func (g *group) fullPrefix() string {
+	if g.parent == nil {
+		return g.prefix
+	}
+
+	return g.parent.fullPrefix() + g.prefix
+}
+
+// APIVersion 创建 API 版本组路由，继承当前组路由的前缀和中间件，路由注册到版本路由表中
+// 例如: a.Group("/api").APIVersion("2").Get("/users", handler)
+func (g *group) APIVersion(version string) zeroapi.Group {
+	return &group{app: g.app, router: g.router.APIVersion(version), parent: g}
+}
+
+// When 创建带有匹配条件的下级组路由，继承当前组路由的前缀，中间件和匹配条件
+// 例如: a.When(zeroapi.ContentType("application/x-protobuf")).Post("/rpc", handler)
+func (g *group) When(predicates ...zeroapi.RoutePredicate) zeroapi.Group {
+	return &group{app: g.app, router: g.router, parent: g, predicates: predicates}
+}
+
+// groupPredicates 依次为上级组路由的匹配条件，当前组路由的匹配条件
+func (g *group) groupPredicates() []zeroapi.RoutePredicate {
+	var predicates []zeroapi.RoutePredicate
+	for p := g; p != nil; p = p.parent {
+		predicates = append(p.predicates[:len(p.predicates):len(p.predicates)], predicates...)
+	}
+
+	return predicates
 }
 
 // Use 添加 Group 级别 中间件
@@ -33,56 +108,170 @@ func (g *group) Use(handlers ...zeroapi.Handler) zeroapi.Group {
 	return g
 }
 
+// groupHandlers 依次为上级组路由的中间件，当前组路由的中间件，路由处理函数
 func (g *group) groupHandlers(handlers ...zeroapi.Handler) []zeroapi.Handler {
-	lenGroupMiddlewares := len(g.middlewares)
+	var middlewares []zeroapi.Handler
+	for p := g; p != nil; p = p.parent {
+		middlewares = append(p.middlewares[:len(p.middlewares):len(p.middlewares)], middlewares...)
+	}
+
+	lenGroupMiddlewares := len(middlewares)
 	lenRouteHandlers := len(handlers)
 
 	_handlers := make([]zeroapi.Handler, lenGroupMiddlewares+lenRouteHandlers)
 
-	copy(_handlers, g.middlewares)
+	copy(_handlers, middlewares)
 	copy(_handlers[lenGroupMiddlewares:], handlers)
 
 	return _handlers
 }
 
+// register 注册路由，严格模式下注册失败会 panic，否则错误会在 Build 时返回
+func (g *group) register(method, path string, handlers ...zeroapi.Handler) {
+	g.match([]string{method}, path, handlers...)
+}
+
+// match 注册路由，组路由级别超时时间与路由在同一次修改中生效
+func (g *group) match(methods []string, path string, handlers ...zeroapi.Handler) {
+	path, predicates, timeout := g.fullPrefix()+path, g.groupPredicates(), g.groupTimeout()
+
+	var err error
+	if r, ok := g.router.(*router); ok {
+		err = r.match(methods, path, predicates, timeout, g.groupHandlers(handlers...)...)
+	} else {
+		err = g.router.MatchWhen(methods, path, predicates, g.groupHandlers(handlers...)...)
+		if err == nil && timeout > 0 {
+			g.router.Timeout(timeout)
+		}
+	}
+
+	if err != nil && g.router.Mode()&zeroapi.RouterModeStrict != 0 {
+		panic(err)
+	}
+}
+
+// groupTimeout 当前组路由或者最近的上级组路由的超时时间
+func (g *group) groupTimeout() time.Duration {
+	for p := g; p != nil; p = p.parent {
+		if p.timeout > 0 {
+			return p.timeout
+		}
+	}
+
+	return 0
+}
+
 // Get method = "GET"
 func (g *group) Get(path string, handlers ...zeroapi.Handler) zeroapi.Group {
-	g.app.Get(g.prefix+path, g.groupHandlers(handlers...)...)
+	g.register(zeroapi.MethodGet, path, handlers...)
 	return g
 }
 
 // Post method = "POST"
 func (g *group) Post(path string, handlers ...zeroapi.Handler) zeroapi.Group {
-	g.app.Post(g.prefix+path, g.groupHandlers(handlers...)...)
+	g.register(zeroapi.MethodPost, path, handlers...)
 	return g
 }
 
 // Put method = "PUT"
 func (g *group) Put(path string, handlers ...zeroapi.Handler) zeroapi.Group {
-	g.app.Put(g.prefix+path, g.groupHandlers(handlers...)...)
+	g.register(zeroapi.MethodPut, path, handlers...)
 	return g
 }
 
 // Delete method = "DELETE"
 func (g *group) Delete(path string, handlers ...zeroapi.Handler) zeroapi.Group {
-	g.app.Delete(g.prefix+path, g.groupHandlers(handlers...)...)
+	g.register(zeroapi.MethodDelete, path, handlers...)
 	return g
 }
 
 // Head method = "HEAD"
 func (g *group) Head(path string, handlers ...zeroapi.Handler) zeroapi.Group {
-	g.app.Head(g.prefix+path, g.groupHandlers(handlers...)...)
+	g.register(zeroapi.MethodHead, path, handlers...)
 	return g
 }
 
 // Patch method = "PATCH"
 func (g *group) Patch(path string, handlers ...zeroapi.Handler) zeroapi.Group {
-	g.app.Patch(g.prefix+path, g.groupHandlers(handlers...)...)
+	g.register(zeroapi.MethodPatch, path, handlers...)
 	return g
 }
 
 // Options method = "OPTIONS"
 func (g *group) Options(path string, handlers ...zeroapi.Handler) zeroapi.Group {
-	g.app.Options(g.prefix+path, g.groupHandlers(handlers...)...)
+	g.register(zeroapi.MethodOptions, path, handlers...)
+	return g
+}
+
+// Any 同时注册 GET, POST, PUT, DELETE, HEAD, PATCH, OPTIONS
+func (g *group) Any(path string, handlers ...zeroapi.Handler) zeroapi.Group {
+	g.register(zeroapi.MethodAny, path, handlers...)
+	return g
+}
+
+// Handle 注册任意 Method，包括 CONNECT，TRACE 以及自定义 Method
+func (g *group) Handle(method, path string, handlers ...zeroapi.Handler) zeroapi.Group {
+	g.register(method, path, handlers...)
+	return g
+}
+
+// Match 同时注册多个 Method，Name 和 Meta 作用于所有 Method
+func (g *group) Match(methods []string, path string, handlers ...zeroapi.Handler) zeroapi.Group {
+	g.match(methods, path, handlers...)
+	return g
+}
+
+// Name 为最近一次注册的路由命名
+func (g *group) Name(name string) zeroapi.Group {
+	if !g.router.Name(name) {
+		g.app.Logger().Errorf("route name \"%s\" is ignored, it is empty, duplicated, or the route is not registered", name)
+	}
+	return g
+}
+
+// Meta 为最近一次注册的路由添加元数据
+func (g *group) Meta(key string, value interface{}) zeroapi.Group {
+	g.router.Meta(key, value)
+	return g
+}
+
+// Timeout 为最近一次注册的路由设置超时时间
+func (g *group) Timeout(timeout time.Duration) zeroapi.Group {
+	g.router.Timeout(timeout)
+	return g
+}
+
+// UseTimeout 设置组路由级别超时时间，与 Use 相同，只作用于之后注册的路由，包括下级组路由
+// 例如: a.Group("/report").UseTimeout(3 * time.Second)
+func (g *group) UseTimeout(timeout time.Duration) zeroapi.Group {
+	g.timeout = timeout
+	return g
+}
+
+// Static 添加静态资源服务
+// prefix 静态资源路由前缀，会添加组路由的前缀
+// path 资源真实位置(绝对路径，相对路径)
+func (g *group) Static(prefix, path string) zeroapi.Group {
+	if path == "" {
+		path = "."
+	}
+
+	f := func(ctx zeroapi.Context) {
+		fileName, err := url.PathUnescape(ctx.Dynamic("*"))
+		if fileName == "" || err != nil {
+			ctx.NotFound()
+			return
+		}
+		path := filepath.Join(path, _path.Clean("/"+fileName))
+		ctx.DownloadFile(path, fileName)
+	}
+
+	if prefix == "/" {
+		g.register(zeroapi.MethodGet, prefix+"*", f)
+		return g
+	}
+
+	g.register(zeroapi.MethodGet, prefix+"/*", f)
+
 	return g
 }
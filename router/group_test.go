@@ -1,8 +1,10 @@
 package router_test
 
 import (
+	"strings"
 	"testing"
 
+	zeroapi "github.com/zerogo-hub/zero-api"
 	zeroapp "github.com/zerogo-hub/zero-api/app"
 	zerorouter "github.com/zerogo-hub/zero-api/router"
 )
@@ -32,7 +34,80 @@ func TestGroup(t *testing.T) {
 	g.Head("/", emptyHandle)
 	g.Options("/", emptyHandle)
 
-	if !a.Router().Build() {
-		t.Fatal("build failed")
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestGroupAny(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Prefix("/api")
+
+	g := a.Group("/dav").Use(emptyHandle)
+	g.Any("/any", emptyHandle).Meta("perm", "admin")
+	g.Handle("PROPFIND", "/files", emptyHandle)
+	g.Match([]string{zeroapi.MethodGet, zeroapi.MethodPost}, "/login", emptyHandle).Name("login")
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := r.Allowed("/api/dav/any")
+	if strings.Join(allowed, ",") != "GET,POST,PUT,DELETE,HEAD,PATCH,OPTIONS" {
+		t.Fatalf("invalid allowed: %v", allowed)
+	}
+
+	for _, method := range allowed {
+		route, _ := r.LookupRoute(method, "/api/dav/any")
+		if route == nil || route.Meta("perm") != "admin" || len(route.Handlers()) != 2 {
+			t.Fatalf("invalid route: %s", method)
+		}
+	}
+
+	if handlers, _ := r.Lookup(zeroapi.MethodConn, "/api/dav/any"); handlers != nil {
+		t.Fatal("CONNECT should not be registered by Any")
+	}
+
+	if handlers, _ := r.Lookup("PROPFIND", "/api/dav/files"); len(handlers) != 2 {
+		t.Fatal("custom method failed")
+	}
+
+	if allowed := r.Allowed("/api/dav/login"); strings.Join(allowed, ",") != "GET,POST" {
+		t.Fatalf("invalid allowed: %v", allowed)
+	}
+	if url, err := r.URL("login", nil, nil); err != nil || url != "/api/dav/login" {
+		t.Fatalf("invalid url: %s %v", url, err)
+	}
+}
+
+func TestGroupNested(t *testing.T) {
+	a := zeroapp.NewApp()
+
+	api := a.Group("api/").Use(emptyHandle)
+	v1 := api.Group("/v1").Use(emptyHandle, emptyHandle)
+	admin := v1.Group("admin")
+	admin.Get("/users", emptyHandle)
+
+	// 设置 App.Prefix 之后注册的路由才会有该前缀
+	a.Prefix("/root")
+	if admin.Prefix() != "/root/api/v1/admin" {
+		t.Fatalf("invalid prefix: %s", admin.Prefix())
+	}
+	admin.Static("/assets", ".")
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/api/v1/admin/users"); len(handlers) != 4 {
+		t.Fatalf("invalid handlers: %d", len(handlers))
+	}
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/api/v1/users"); handlers != nil {
+		t.Fatal("should not match")
+	}
+	if handlers, dynamic := r.Lookup(zeroapi.MethodGet, "/root/api/v1/admin/assets/a.css"); len(handlers) != 4 || dynamic["*"] != "a.css" {
+		t.Fatal("static failed")
 	}
 }
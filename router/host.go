@@ -0,0 +1,164 @@
+package router
+
+import (
+	"strings"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// root 获取默认路由表
+func (r *router) root() *router {
+	for r.parent != nil {
+		r = r.parent
+	}
+
+	return r
+}
+
+// Host 获取域名对应的路由表，不存在时创建，pattern 为空时返回默认路由表
+// 域名中可以使用 {name} 匹配一级域名，匹配结果通过 ctx.Dynamic("name") 获取
+// 域名不区分大小写，忽略端口
+// 例如: {tenant}.example.com 匹配 yaha.example.com，tenant="yaha"
+func (r *router) Host(pattern string) zeroapi.Router {
+	root := r.root()
+
+	pattern = normalizeHost(pattern)
+	if pattern == "" {
+		return root
+	}
+
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	hosts := root.hostList()
+	for _, h := range hosts {
+		if h.host == pattern {
+			return h
+		}
+	}
+
+	h := &router{
+		app:        root.app,
+		validators: root.validators,
+		registered: make(map[string]bool),
+		conditions: make(map[string]int),
+		parent:     root,
+		host:       pattern,
+
+		validatorFactories: root.validatorFactories,
+	}
+	h.table.Store(newRouteTable())
+
+	// 复制后替换，MatchHost 不需要加锁
+	hosts = append(hosts[:len(hosts):len(hosts)], h)
+	root.hosts.Store(&hosts)
+
+	return h
+}
+
+// hostList 获取所有域名路由表，域名路由表返回 nil
+func (r *router) hostList() []*router {
+	if hosts := r.hosts.Load(); hosts != nil {
+		return *hosts
+	}
+
+	return nil
+}
+
+// HostPattern 获取路由表对应的域名，默认路由表返回 ""
+func (r *router) HostPattern() string {
+	return r.host
+}
+
+// MatchHost 根据请求的域名选择路由表，同时返回域名中的参数
+// 优先匹配不含参数的域名，其次按照注册顺序匹配含有参数的域名，都不匹配时返回默认路由表
+func (r *router) MatchHost(host string) (zeroapi.Router, map[string]string) {
+	root := r.root()
+
+	hosts := root.hostList()
+	if len(hosts) == 0 {
+		return root, nil
+	}
+
+	host = normalizeHost(host)
+
+	for _, h := range hosts {
+		if h.host == host {
+			return h, nil
+		}
+	}
+
+	for _, h := range hosts {
+		if dynamic, ok := matchHost(h.host, host); ok {
+			return h, dynamic
+		}
+	}
+
+	return root, nil
+}
+
+// normalizeHost 转为小写，去除端口和末尾的 "."
+func normalizeHost(host string) string {
+	host = strings.ToLower(strings.TrimSpace(host))
+
+	// 只含有一个 ":" 时才是端口，避免误处理 IPv6
+	if strings.Count(host, ":") == 1 {
+		host = host[:strings.IndexByte(host, ':')]
+	}
+
+	return strings.TrimRight(host, ".")
+}
+
+// matchHost 使用 pattern 按照 "." 逐级匹配域名，{name} 匹配任意非空的一级域名
+func matchHost(pattern, host string) (map[string]string, bool) {
+	if !strings.Contains(pattern, "{") {
+		return nil, false
+	}
+
+	var dynamic map[string]string
+
+	for {
+		var label, hostLabel string
+		label, pattern, _ = strings.Cut(pattern, ".")
+		hostLabel, host, _ = strings.Cut(host, ".")
+
+		if name, ok := hostParam(label); ok {
+			if hostLabel == "" {
+				return nil, false
+			}
+
+			if dynamic == nil {
+				dynamic = make(map[string]string, 1)
+			}
+			dynamic[name] = hostLabel
+		} else if label != hostLabel {
+			return nil, false
+		}
+
+		if pattern == "" || host == "" {
+			break
+		}
+	}
+
+	if pattern != "" || host != "" {
+		return nil, false
+	}
+
+	return dynamic, true
+}
+
+// hostParam 解析域名参数，格式为 {name}
+func hostParam(label string) (string, bool) {
+	if len(label) < 3 || label[0] != '{' || label[len(label)-1] != '}' {
+		return "", false
+	}
+
+	name := label[1 : len(label)-1]
+	for i := 0; i < len(name); i++ {
+		if !isNameChar(name[i]) {
+			return "", false
+		}
+	}
+
+	return name, true
+}
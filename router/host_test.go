@@ -0,0 +1,105 @@
+package router_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func TestMatchHost(t *testing.T) {
+	r := zeroapp.NewApp().Router()
+
+	api := r.Host("API.example.com:8080")
+	tenant := r.Host("{tenant}.example.com")
+	deep := r.Host("{app}.{region}.example.com")
+
+	if r.Host("api.example.com") != api || api.HostPattern() != "api.example.com" {
+		t.Fatal("host should be normalized")
+	}
+	if r.Host("") != r || api.Host("") != r {
+		t.Fatal("empty pattern should return default router")
+	}
+
+	tests := []struct {
+		host    string
+		router  zeroapi.Router
+		dynamic map[string]string
+	}{
+		{"api.example.com", api, nil},
+		{"Api.Example.com:443", api, nil},
+		{"yaha.example.com", tenant, map[string]string{"tenant": "yaha"}},
+		{"shop.eu.example.com", deep, map[string]string{"app": "shop", "region": "eu"}},
+		{"example.com", r, nil},
+		{"yaha.example.org", r, nil},
+		{"a.b.c.example.com", r, nil},
+	}
+
+	for _, test := range tests {
+		router, dynamic := r.MatchHost(test.host)
+		if router != test.router {
+			t.Fatalf("%s: invalid router: %s", test.host, router.HostPattern())
+		}
+
+		if len(dynamic) != len(test.dynamic) {
+			t.Fatalf("%s: invalid dynamic: %v", test.host, dynamic)
+		}
+		for key, value := range test.dynamic {
+			if dynamic[key] != value {
+				t.Fatalf("%s: invalid dynamic: %v", test.host, dynamic)
+			}
+		}
+	}
+}
+
+func TestHostRoutes(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Prefix("/v1")
+	a.Get("/user", emptyHandle)
+	a.Host("{tenant}.example.com").Get("/user/:id|isNum|", emptyHandle).Name("tenantUser")
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := r.Routes()
+	if len(routes) != 2 {
+		t.Fatalf("invalid routes: %+v", routes)
+	}
+	if routes[0].Host != "" || routes[0].Path != "/v1/user" {
+		t.Fatalf("invalid route: %+v", routes[0])
+	}
+	if routes[1].Host != "{tenant}.example.com" || routes[1].Path != "/v1/user/:id|isNum|" || routes[1].Name != "tenantUser" {
+		t.Fatalf("invalid route: %+v", routes[1])
+	}
+
+	host := r.Host("{tenant}.example.com")
+	if handlers, _ := host.Lookup(zeroapi.MethodGet, "/v1/user/1001"); handlers == nil {
+		t.Fatal("lookup failed")
+	}
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/v1/user/1001"); handlers != nil {
+		t.Fatal("default router should not contain host routes")
+	}
+
+	if url, err := host.URL("tenantUser", map[string]string{"id": "1001"}, nil); err != nil || url != "/v1/user/1001" {
+		t.Fatalf("invalid url: %s %v", url, err)
+	}
+
+	var b bytes.Buffer
+	r.Dump(&b)
+	if !strings.Contains(b.String(), "GET {tenant}.example.com\n") {
+		t.Fatalf("invalid dump:\n%s", b.String())
+	}
+}
+
+func TestHostBuildFailed(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Host("api.example.com").Get("/user/:id|isNun|", emptyHandle)
+
+	if a.Router().Build() == nil {
+		t.Fatal("build should fail")
+	}
+}
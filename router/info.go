@@ -0,0 +1,293 @@
+package router
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// Routes 获取所有已注册的路由，按照域名，路径，API 版本，Method 排序
+// 默认路由表同时包含所有域名路由表中的路由，默认路由表和域名路由表同时包含其版本路由表中的路由
+func (r *router) Routes() []zeroapi.RouteInfo {
+	t := r.loadTable()
+
+	names := make(map[string]string, len(t.names))
+	for name, path := range t.names {
+		names[path] = name
+	}
+
+	var infos []zeroapi.RouteInfo
+
+	for method, re := range t.routes {
+		walk(re.Root(), func(node zeroapi.RouteNode) {
+			rn, ok := node.(*routeNode)
+			if !ok || !rn.IsHandler() {
+				return
+			}
+
+			if len(rn.handlers) > 0 {
+				infos = append(infos, r.routeInfo(t, method, rn.fullPath, names, rn.handlers, metaKey(method, rn.fullPath)))
+			}
+
+			for i, v := range rn.variants {
+				info := r.routeInfo(t, method, rn.fullPath, names, v.handlers, variantKey(method, rn.fullPath, i))
+				info.Predicates = len(v.predicates)
+				infos = append(infos, info)
+			}
+		})
+	}
+
+	for _, v := range r.versionList() {
+		infos = append(infos, v.Routes()...)
+	}
+
+	for _, h := range r.hostList() {
+		infos = append(infos, h.Routes()...)
+	}
+
+	order := make(map[string]int)
+	for i, method := range zeroapi.AllMethods() {
+		order[method] = i + 1
+	}
+
+	// 同一个路由中，没有匹配条件的路由在前，带有匹配条件的路由按照注册顺序排列
+	sort.SliceStable(infos, func(i, j int) bool {
+		if infos[i].Host != infos[j].Host {
+			return infos[i].Host < infos[j].Host
+		}
+
+		if infos[i].Path != infos[j].Path {
+			return infos[i].Path < infos[j].Path
+		}
+
+		if infos[i].Version != infos[j].Version {
+			return infos[i].Version < infos[j].Version
+		}
+
+		oi, oj := order[infos[i].Method], order[infos[j].Method]
+		if oi != oj {
+			// 自定义 Method 排在最后
+			return oi != 0 && (oj == 0 || oi < oj)
+		}
+
+		return infos[i].Method < infos[j].Method
+	})
+
+	return infos
+}
+
+// routeInfo 生成一个路由的信息，key 为元数据的 key，见 metaKey，variantKey
+func (r *router) routeInfo(t *routeTable, method, path string, names map[string]string, handlers []zeroapi.Handler, key string) zeroapi.RouteInfo {
+	info := zeroapi.RouteInfo{
+		Host:         r.host,
+		Version:      r.version,
+		Method:       method,
+		Path:         path,
+		Name:         names[path],
+		Params:       paramInfos(path),
+		HandlerCount: len(handlers),
+		HandlerNames: make([]string, 0, len(handlers)),
+		Meta:         t.metas[key],
+		Timeout:      t.timeouts[key],
+	}
+
+	for _, handler := range handlers {
+		info.HandlerNames = append(info.HandlerNames, handlerName(handler))
+	}
+
+	return info
+}
+
+// Dump 打印每一种 Method 的基数树结构，建议在 Build 之后调用
+//
+// 示例:
+//
+//	GET
+//	└── /blog
+//	    ├── /list [1]
+//	    └── /:id(^\d+$) [2] dynamic=id regexp
+//
+// 默认路由表之后依次打印所有版本路由表和域名路由表，Method 之后附带域名和版本，例如 "GET {tenant}.example.com v2"
+// 带有匹配条件的路由附带条件路由的数量，例如 "/rpc [1] when=2"
+func (r *router) Dump(w io.Writer) {
+	t := r.loadTable()
+
+	for _, method := range sortMethods(t.methods()) {
+		title := []string{method}
+		if r.host != "" {
+			title = append(title, r.host)
+		}
+		if r.version != "" {
+			title = append(title, "v"+r.version)
+		}
+		fmt.Fprintln(w, strings.Join(title, " "))
+
+		root := t.routes[method].Root()
+		if root.Path() == "" && !root.IsHandler() {
+			// 根节点没有内容，直接打印子节点
+			dumpChildren(w, root, "")
+			continue
+		}
+
+		dumpNode(w, root, "", true)
+	}
+
+	for _, v := range r.versionList() {
+		v.Dump(w)
+	}
+
+	for _, h := range r.hostList() {
+		h.Dump(w)
+	}
+}
+
+func dumpChildren(w io.Writer, node zeroapi.RouteNode, prefix string) {
+	children := node.Children()
+	for i, child := range children {
+		dumpNode(w, child, prefix, i == len(children)-1)
+	}
+}
+
+func dumpNode(w io.Writer, node zeroapi.RouteNode, prefix string, isLast bool) {
+	branch, indent := "├── ", "│   "
+	if isLast {
+		branch, indent = "└── ", "    "
+	}
+
+	fmt.Fprintf(w, "%s%s%s%s\n", prefix, branch, node.Path(), nodeDescription(node))
+
+	dumpChildren(w, node, prefix+indent)
+}
+
+// nodeDescription 节点描述，包括处理函数数量，节点类型
+func nodeDescription(node zeroapi.RouteNode) string {
+	var desc []string
+
+	if handlers := node.Handlers(); len(handlers) > 0 {
+		desc = append(desc, fmt.Sprintf("[%d]", len(handlers)))
+	}
+
+	if rn, ok := node.(*routeNode); ok && len(rn.variants) > 0 {
+		desc = append(desc, fmt.Sprintf("when=%d", len(rn.variants)))
+	}
+
+	if rn, ok := node.(*routeNode); ok && rn.IsDynamic() {
+		desc = append(desc, "dynamic="+strings.Join(rn.dynamicNames(), ","))
+	}
+	if node.IsOptional() {
+		desc = append(desc, "optional")
+	}
+	if rn, ok := node.(*routeNode); ok && rn.IsWildcard() {
+		desc = append(desc, "wildcard="+rn.dynamicName)
+	}
+	if node.IsRegexp() {
+		desc = append(desc, "regexp")
+	}
+	if node.IsValidator() {
+		desc = append(desc, "validator")
+	}
+
+	if len(desc) == 0 {
+		return ""
+	}
+
+	return " " + strings.Join(desc, " ")
+}
+
+// walk 深度优先遍历节点
+func walk(node zeroapi.RouteNode, f func(node zeroapi.RouteNode)) {
+	f(node)
+
+	for _, child := range node.Children() {
+		walk(child, f)
+	}
+}
+
+// paramInfos 解析路由全路径中的动态参数与通配符
+func paramInfos(fullPath string) []zeroapi.RouteParamInfo {
+	var params []zeroapi.RouteParamInfo
+
+	for _, path := range buildPath(fullPath) {
+		if len(path) < 2 {
+			continue
+		}
+
+		if path[1] == WildcardCharacter {
+			params = append(params, paramInfo(path))
+			break
+		}
+
+		segment, isOptional, defaultValue := parseOptional(path)
+		if isOptional {
+			info := paramInfo(segment)
+			info.Optional = true
+			info.Default = defaultValue
+			params = append(params, info)
+			continue
+		}
+
+		tokens, err := splitSegment(path[1:])
+		if err != nil {
+			continue
+		}
+
+		for _, token := range tokens {
+			if token[0] == DynamicCharacter {
+				params = append(params, paramInfo("/"+token))
+			}
+		}
+	}
+
+	return params
+}
+
+// paramInfo 解析一个动态参数或者通配符节点，例如 /:id(^\d+$)|isNum|less4|，/*filepath+(\.md$)
+func paramInfo(path string) zeroapi.RouteParamInfo {
+	info := zeroapi.RouteParamInfo{}
+
+	node := newChild(path).(*routeNode)
+
+	pos := node.paramEnd()
+	info.Name = path[2:node.nameEnd()]
+	if node.IsWildcard() && info.Name == "" {
+		info.Name = string(WildcardCharacter)
+	}
+
+	if pos < len(path) && path[pos] == '(' {
+		posEnd := closeParen(path, pos)
+		if posEnd == -1 {
+			return info
+		}
+
+		info.Regexp = path[pos+1 : posEnd]
+		if re, err := regexp.Compile(info.Regexp); err == nil {
+			for _, name := range re.SubexpNames() {
+				if name != "" {
+					info.Captures = append(info.Captures, name)
+				}
+			}
+		}
+		pos = posEnd + 1
+	}
+
+	if posEnd := strings.LastIndex(path, "|"); pos < posEnd {
+		info.Validators = strings.Split(path[pos+1:posEnd], "|")
+	}
+
+	return info
+}
+
+// handlerName 获取函数名称
+func handlerName(handler zeroapi.Handler) string {
+	if f := runtime.FuncForPC(reflect.ValueOf(handler).Pointer()); f != nil {
+		return f.Name()
+	}
+
+	return ""
+}
@@ -0,0 +1,118 @@
+package router_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func TestRouterRoutes(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+	r.RegisterRouterValidator("less4", less4)
+
+	a.Post("/blog/:id(^\\d+$)|less4|", emptyHandle)
+	a.Get("/blog/:id(^\\d+$)|less4|", emptyHandle, emptyHandle).Name("blog")
+	a.Get("/static/*", emptyHandle)
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := r.Routes()
+	if len(routes) != 3 {
+		t.Fatalf("invalid routes: %d", len(routes))
+	}
+
+	blog := routes[0]
+	if blog.Method != zeroapi.MethodGet || blog.Path != "/blog/:id(^\\d+$)|less4|" || blog.Name != "blog" {
+		t.Fatalf("invalid route: %+v", blog)
+	}
+
+	if blog.HandlerCount != 2 || !strings.HasSuffix(blog.HandlerNames[0], "router_test.emptyHandle") {
+		t.Fatalf("invalid handlers: %+v", blog)
+	}
+
+	if len(blog.Params) != 1 {
+		t.Fatalf("invalid params: %+v", blog.Params)
+	}
+
+	param := blog.Params[0]
+	if param.Name != "id" || param.Regexp != "^\\d+$" || len(param.Validators) != 1 || param.Validators[0] != "less4" {
+		t.Fatalf("invalid param: %+v", param)
+	}
+
+	if routes[1].Method != zeroapi.MethodPost {
+		t.Fatalf("invalid order: %+v", routes[1])
+	}
+
+	if static := routes[2]; len(static.Params) != 1 || static.Params[0].Name != "*" {
+		t.Fatalf("invalid wildcard: %+v", static)
+	}
+}
+
+func TestRouterDump(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+
+	a.Get("/blog/list", emptyHandle)
+	a.Get("/blog/:id(^\\d+$)", emptyHandle, emptyHandle)
+	a.Post("/user", emptyHandle)
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	r.Dump(&buf)
+
+	expected := `GET
+└── /blog
+    ├── /list [1]
+    └── /:id(^\d+$) [2] dynamic=id regexp
+POST
+└── /user [1]
+`
+	if buf.String() != expected {
+		t.Fatalf("invalid dump:\n%s", buf.String())
+	}
+}
+
+func TestRouterRoutesPrefix(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+
+	a.Prefix("/api/")
+	a.Get("/user", emptyHandle)
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if routes := r.Routes(); len(routes) != 1 || routes[0].Path != "/api/user" {
+		t.Fatalf("invalid routes: %+v", routes)
+	}
+}
+
+func TestRouterRoutesOptional(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+
+	a.Get("/list/:page?=1", emptyHandle)
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	routes := r.Routes()
+	if len(routes) != 1 || len(routes[0].Params) != 1 {
+		t.Fatalf("invalid routes: %+v", routes)
+	}
+
+	if param := routes[0].Params[0]; param.Name != "page" || !param.Optional || param.Default != "1" {
+		t.Fatalf("invalid param: %+v", param)
+	}
+}
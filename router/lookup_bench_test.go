@@ -0,0 +1,103 @@
+package router_test
+
+import (
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func newBenchRouter(b *testing.B) zeroapi.Router {
+	a := zeroapp.NewApp()
+	a.Get("/", emptyHandle)
+	a.Get("/blog/list", emptyHandle)
+	a.Get("/blog/:id(^\\d+$)", emptyHandle)
+	a.Get("/blog/:id(^\\d+$)/comments/:cid", emptyHandle)
+	a.Get("/files/:name.:ext", emptyHandle)
+	a.Get("/static/*filepath", emptyHandle)
+	a.Get("/archive/:year?/:month?", emptyHandle)
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		b.Fatal(err)
+	}
+
+	return r
+}
+
+func benchmarkLookup(b *testing.B, path string, num int) {
+	r := newBenchRouter(b)
+
+	params := make(zeroapi.Params, 0, 8)
+	if route := r.LookupRouteParams(nil, zeroapi.MethodGet, path, &params); route == nil || len(params) != num {
+		b.Fatalf("lookup %s failed: %v", path, params)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		params = params[:0]
+		if r.LookupRouteParams(nil, zeroapi.MethodGet, path, &params) == nil {
+			b.Fatal("not found")
+		}
+	}
+}
+
+func BenchmarkLookupStatic(b *testing.B) {
+	benchmarkLookup(b, "/blog/list", 0)
+}
+
+func BenchmarkLookupDynamic(b *testing.B) {
+	benchmarkLookup(b, "/blog/1001/comments/42", 2)
+}
+
+func BenchmarkLookupMultiple(b *testing.B) {
+	benchmarkLookup(b, "/files/a.tar.gz", 2)
+}
+
+func BenchmarkLookupWildcard(b *testing.B) {
+	benchmarkLookup(b, "/static/css/app.css", 1)
+}
+
+func BenchmarkLookupOptional(b *testing.B) {
+	benchmarkLookup(b, "/archive/2024", 1)
+}
+
+func TestLookupRouteParams(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/blog/:id(^\\d+$)/comments/:cid", emptyHandle)
+	a.Get("/blog/:name/info", emptyHandle)
+	a.Get("/archive/:year?/:month(^\\d+$)?=1", emptyHandle)
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	params := zeroapi.Params{{Key: "host", Value: "yaha"}}
+
+	if r.LookupRouteParams(nil, zeroapi.MethodGet, "/blog/1001/comments", &params) != nil || len(params) != 1 {
+		t.Fatalf("params should be kept when not found: %v", params)
+	}
+
+	if r.LookupRouteParams(nil, zeroapi.MethodGet, "/blog/yaha/info", &params) == nil || len(params) != 2 {
+		t.Fatalf("backtracking failed: %v", params)
+	}
+	if name, _ := params.Get("name"); name != "yaha" {
+		t.Fatalf("invalid params: %v", params)
+	}
+
+	params = params[:0]
+	if r.LookupRouteParams(nil, zeroapi.MethodGet, "/archive/2024", &params) == nil {
+		t.Fatal("lookup optional failed")
+	}
+	if m := params.Map(); len(m) != 2 || m["year"] != "2024" || m["month"] != "1" {
+		t.Fatalf("invalid params: %v", params)
+	}
+
+	params.Set("year", "2025")
+	if year, _ := params.Get("year"); year != "2025" || len(params) != 2 {
+		t.Fatalf("set params failed: %v", params)
+	}
+}
@@ -0,0 +1,131 @@
+package router
+
+import (
+	"time"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// matchedRoute 实现 zeroapi.MatchedRoute
+type matchedRoute struct {
+	method   string
+	host     string
+	version  string
+	path     string
+	name     string
+	handlers []zeroapi.Handler
+	meta     map[string]interface{}
+	timeout  time.Duration
+}
+
+// Method 注册路由时使用的 HTTP Method
+func (mr *matchedRoute) Method() string {
+	return mr.method
+}
+
+// Host 域名，默认路由表为 ""
+func (mr *matchedRoute) Host() string {
+	return mr.host
+}
+
+// APIVersion API 版本，不是版本路由表中的路由时为 ""
+func (mr *matchedRoute) APIVersion() string {
+	return mr.version
+}
+
+// Path 路由全路径
+func (mr *matchedRoute) Path() string {
+	return mr.path
+}
+
+// Name 路由名称
+func (mr *matchedRoute) Name() string {
+	return mr.name
+}
+
+// Handlers 路由处理函数和路由级别中间件
+func (mr *matchedRoute) Handlers() []zeroapi.Handler {
+	return mr.handlers
+}
+
+// Meta 获取元数据，不存在时返回 nil
+func (mr *matchedRoute) Meta(key string) interface{} {
+	return mr.meta[key]
+}
+
+// Metas 获取所有元数据，不可以修改
+func (mr *matchedRoute) Metas() map[string]interface{} {
+	return mr.meta
+}
+
+// Meta 为最近一次注册的路由添加元数据，同名的 key 会被覆盖
+func (r *router) Meta(key string, value interface{}) bool {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if key == "" || r.lastPath == "" {
+		return false
+	}
+
+	err := r.updateAttrs(func(t *routeTable) {
+		for _, k := range r.lastKeys {
+			meta := t.metas[k]
+			if meta == nil {
+				meta = make(map[string]interface{})
+				t.metas[k] = meta
+			}
+
+			meta[key] = value
+		}
+	})
+
+	return err == nil
+}
+
+// LookupRoute 查找路由，返回匹配成功的路由，未匹配时返回 nil
+// 不检查匹配条件，只返回没有匹配条件的路由，见 LookupRouteFor
+func (r *router) LookupRoute(method, path string) (zeroapi.MatchedRoute, map[string]string) {
+	return r.LookupRouteFor(nil, method, path)
+}
+
+// buildMatchedRoutes 为含有路由处理函数的节点生成路由信息，匹配成功时直接使用
+func (r *router) buildMatchedRoutes(t *routeTable, method string, re Route) {
+	names := make(map[string]string, len(t.names))
+	for name, path := range t.names {
+		names[path] = name
+	}
+
+	walk(re.Root(), func(node zeroapi.RouteNode) {
+		rn, ok := node.(*routeNode)
+		if !ok || !rn.IsHandler() {
+			return
+		}
+
+		if len(rn.handlers) > 0 {
+			rn.route = r.newMatchedRoute(t, method, rn.fullPath, rn.handlers, metaKey(method, rn.fullPath), names)
+		}
+
+		for i, v := range rn.variants {
+			v.route = r.newMatchedRoute(t, method, rn.fullPath, v.handlers, variantKey(method, rn.fullPath, i), names)
+		}
+	})
+}
+
+// newMatchedRoute key 为元数据的 key，见 metaKey，variantKey
+func (r *router) newMatchedRoute(t *routeTable, method, path string, handlers []zeroapi.Handler, key string, names map[string]string) *matchedRoute {
+	return &matchedRoute{
+		method:   method,
+		host:     r.host,
+		version:  r.version,
+		path:     path,
+		name:     names[path],
+		handlers: handlers,
+		meta:     t.metas[key],
+		timeout:  t.timeouts[key],
+	}
+}
+
+func metaKey(method, path string) string {
+	return method + " " + path
+}
@@ -0,0 +1,62 @@
+package router_test
+
+import (
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func TestMeta(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/admin/:id", emptyHandle).Name("admin").Meta("perm", "admin").Meta("tags", []string{"admin", "user"})
+	a.Post("/admin/:id", emptyHandle).Meta("perm", "root")
+	a.Get("/public", emptyHandle)
+	a.Group("/api").Get("/user", emptyHandle).Meta("summary", "list users")
+
+	r := a.Router()
+	if r.Meta("perm", "none") != true {
+		t.Fatal("meta failed")
+	}
+	if r2 := zeroapp.NewApp().Router(); r2.Register(zeroapi.MethodGet, "", emptyHandle) == nil || r2.Meta("perm", "none") {
+		t.Fatal("meta should fail without a registered route")
+	}
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	route, dynamic := r.LookupRoute(zeroapi.MethodGet, "/admin/1001")
+	if route == nil || dynamic["id"] != "1001" {
+		t.Fatal("lookup failed")
+	}
+	if route.Method() != zeroapi.MethodGet || route.Path() != "/admin/:id" || route.Name() != "admin" || len(route.Handlers()) != 1 {
+		t.Fatalf("invalid route: %s %s %s", route.Method(), route.Path(), route.Name())
+	}
+	if route.Meta("perm") != "admin" || len(route.Meta("tags").([]string)) != 2 || route.Meta("none") != nil {
+		t.Fatalf("invalid meta: %v", route.Metas())
+	}
+
+	if route, _ := r.LookupRoute(zeroapi.MethodPost, "/admin/1001"); route == nil || route.Meta("perm") != "root" {
+		t.Fatal("invalid post meta")
+	}
+
+	if route, _ := r.LookupRoute(zeroapi.MethodGet, "/public"); route == nil || route.Metas() != nil {
+		t.Fatal("invalid public meta")
+	}
+
+	// r.Meta 作用于最近一次注册的路由 /api/user
+	if route, _ := r.LookupRoute(zeroapi.MethodGet, "/api/user"); route == nil || route.Meta("summary") != "list users" || route.Meta("perm") != "none" {
+		t.Fatal("invalid group meta")
+	}
+
+	if route, _ := r.LookupRoute(zeroapi.MethodGet, "/none"); route != nil {
+		t.Fatal("should not match")
+	}
+
+	for _, info := range r.Routes() {
+		if info.Path == "/admin/:id" && info.Method == zeroapi.MethodGet && info.Meta["perm"] != "admin" {
+			t.Fatalf("invalid route info: %+v", info)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package router
+
+import (
+	"net/http"
+	"strconv"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// routeVariant 带有匹配条件的路由处理函数，见 App.When
+type routeVariant struct {
+	// predicates 匹配条件，全部满足时才会使用
+	predicates []zeroapi.RoutePredicate
+
+	// handlers 路由处理函数 + 路由级别中间件
+	handlers []zeroapi.Handler
+
+	// route 匹配成功时返回的路由信息，在 Router.Build 中生成
+	route *matchedRoute
+}
+
+// match 是否满足全部匹配条件
+func (v *routeVariant) match(req *http.Request) bool {
+	for _, predicate := range v.predicates {
+		if predicate != nil && !predicate(req) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// InsertWhen 添加带有匹配条件的路由，同一个路由可以添加多次
+func (re *route) InsertWhen(path string, predicates []zeroapi.RoutePredicate, handlers ...zeroapi.Handler) {
+	handlers = handlersWithoutNil(handlers...)
+	if len(handlers) == 0 {
+		return
+	}
+
+	rn := re.root.(*routeNode).put(path, buildPath(path), 0)
+	rn.variants = append(rn.variants, &routeVariant{predicates: predicates, handlers: handlers})
+
+	// 需要重新 Build
+	re.statics = nil
+}
+
+// MatchWhen 为多个 Method 注册带有匹配条件的路由，路径匹配之后检查条件，全部满足时才会使用
+// 同一个路由可以注册多次，按照注册顺序检查，都不满足时使用没有匹配条件的路由，没有时视为未匹配
+// predicates 为空时与 Match 相同
+func (r *router) MatchWhen(methods []string, path string, predicates []zeroapi.RoutePredicate, handlers ...zeroapi.Handler) error {
+	return r.match(methods, path, predicates, 0, handlers...)
+}
+
+// LookupRouteFor 查找路由，并使用请求检查匹配条件，返回匹配成功的路由，未匹配时返回 nil
+func (r *router) LookupRouteFor(req *http.Request, method, path string) (zeroapi.MatchedRoute, map[string]string) {
+	var params zeroapi.Params
+
+	route := r.LookupRouteParams(req, method, path, &params)
+	if route == nil {
+		return nil, nil
+	}
+
+	return route, params.Map()
+}
+
+// LookupRouteParams 与 LookupRouteFor 相同，动态参数依次添加到 params 末尾，req 为 nil 时不检查匹配条件
+// 未匹配时 params 保持不变，Build 之后匹配路由不需要分配内存
+// 开启 zeroapi.RouterModeUseRawPath 时，path 为未解码的路径，动态参数的值先解码，再检查正则表达式和验证函数，通配符除外
+func (r *router) LookupRouteParams(req *http.Request, method, path string, params *zeroapi.Params) zeroapi.MatchedRoute {
+	t := r.loadTable()
+
+	re := t.routes[method]
+	if re == nil {
+		return nil
+	}
+
+	n := len(*params)
+
+	rn := re.(*route).lookup(path, params, r.lookupOption(req, true))
+	if rn == nil {
+		return nil
+	}
+
+	route := r.selectRoute(t, req, method, rn)
+	if route == nil {
+		*params = (*params)[:n]
+		return nil
+	}
+
+	return route
+}
+
+// selectRoute 依次检查带有匹配条件的路由，都不满足时使用没有匹配条件的路由，没有时返回 nil
+func (r *router) selectRoute(t *routeTable, req *http.Request, method string, rn *routeNode) zeroapi.MatchedRoute {
+	if req != nil {
+		for i, v := range rn.variants {
+			if !v.match(req) {
+				continue
+			}
+
+			if v.route == nil {
+				// 未调用 Build
+				return r.newMatchedRoute(t, method, rn.fullPath, v.handlers, variantKey(method, rn.fullPath, i), nil)
+			}
+
+			return v.route
+		}
+	}
+
+	if len(rn.handlers) == 0 {
+		return nil
+	}
+
+	if rn.route == nil {
+		// 未调用 Build
+		return r.newMatchedRoute(t, method, rn.fullPath, rn.handlers, metaKey(method, rn.fullPath), nil)
+	}
+
+	return rn.route
+}
+
+// variantKey 带有匹配条件的路由的元数据 key，Method + " " + 路由全路径 + "#" + 序号
+func variantKey(method, path string, i int) string {
+	return metaKey(method, path) + "#" + strconv.Itoa(i)
+}
@@ -0,0 +1,148 @@
+package router_test
+
+import (
+	"bytes"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func TestRouterPredicate(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Post("/rpc", emptyHandle).Name("rpc")
+	a.When(zeroapi.ContentType("application/x-protobuf")).Post("/rpc", emptyHandle, emptyHandle).Meta("codec", "protobuf")
+	a.When(zeroapi.Headers("X-Codec", "")).Post("/rpc", emptyHandle, emptyHandle, emptyHandle)
+	a.Group("/api").When(zeroapi.Queries("format", "csv")).Get("/export", emptyHandle)
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(zeroapi.MethodPost, "/rpc", nil)
+	req.Header.Set("Content-Type", "Application/X-Protobuf; charset=utf-8")
+	req.Header.Set("X-Codec", "1")
+
+	route, _ := r.LookupRouteFor(req, zeroapi.MethodPost, "/rpc")
+	if route == nil || len(route.Handlers()) != 2 || route.Meta("codec") != "protobuf" || route.Name() != "rpc" {
+		t.Fatal("first matched predicate should be used")
+	}
+
+	req.Header.Del("Content-Type")
+	if route, _ = r.LookupRouteFor(req, zeroapi.MethodPost, "/rpc"); route == nil || len(route.Handlers()) != 3 {
+		t.Fatal("header predicate failed")
+	}
+
+	if route, _ = r.LookupRoute(zeroapi.MethodPost, "/rpc"); route == nil || len(route.Handlers()) != 1 || route.Meta("codec") != nil {
+		t.Fatal("lookup route should ignore predicates")
+	}
+
+	req = httptest.NewRequest(zeroapi.MethodGet, "/api/export?format=json", nil)
+	if route, _ = r.LookupRouteFor(req, zeroapi.MethodGet, "/api/export"); route != nil {
+		t.Fatal("route without default handlers should not match")
+	}
+	if allowed := r.Allowed("/api/export"); len(allowed) != 1 || allowed[0] != zeroapi.MethodGet {
+		t.Fatalf("invalid allowed: %v", allowed)
+	}
+
+	req = httptest.NewRequest(zeroapi.MethodGet, "/api/export?format=csv", nil)
+	if route, _ = r.LookupRouteFor(req, zeroapi.MethodGet, "/api/export"); route == nil {
+		t.Fatal("query predicate failed")
+	}
+
+	var predicates []int
+	for _, info := range r.Routes() {
+		predicates = append(predicates, info.Predicates)
+	}
+	if len(predicates) != 4 || predicates[0] != 1 || predicates[1] != 0 || predicates[2] != 1 || predicates[3] != 1 {
+		t.Fatalf("invalid routes: %v", predicates)
+	}
+
+	var b bytes.Buffer
+	r.Dump(&b)
+	if !strings.Contains(b.String(), "/rpc [1] when=2\n") {
+		t.Fatalf("invalid dump: %s", b.String())
+	}
+
+	if err := r.Remove(zeroapi.MethodGet, "/api/export"); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Remove(zeroapi.MethodGet, "/api/export"); !errors.Is(err, zeroapi.ErrRouteNotFound) {
+		t.Fatalf("invalid error: %v", err)
+	}
+	if r.Allowed("/api/export") != nil {
+		t.Fatal("remove conditional route failed")
+	}
+}
+
+func TestPredicates(t *testing.T) {
+	req := httptest.NewRequest(zeroapi.MethodGet, "/?a=1&b=", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+
+	tests := []struct {
+		predicate zeroapi.RoutePredicate
+		expected  bool
+	}{
+		{zeroapi.Queries("a", "1", "b", ""), true},
+		{zeroapi.Queries("a", "2"), false},
+		{zeroapi.Queries("c", ""), false},
+		{zeroapi.Headers("X-Forwarded-Proto", "https"), true},
+		{zeroapi.Headers("x-forwarded-proto", ""), true},
+		{zeroapi.Headers("X-Real-IP", ""), false},
+		{zeroapi.ContentType("application/json"), false},
+		{zeroapi.Scheme("HTTPS"), false},
+		{zeroapi.Scheme("http"), true},
+		{zeroapi.ForwardedScheme([]string{"192.0.2.0/24"}, "https"), true},
+		{zeroapi.ForwardedScheme([]string{"10.0.0.1", "::1"}, "https"), false},
+		{zeroapi.ForwardedScheme(nil, "http"), true},
+	}
+	for i, test := range tests {
+		if test.predicate(req) != test.expected {
+			t.Fatalf("test %d failed", i)
+		}
+	}
+}
+
+func TestRouterPredicateFallback(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.When(zeroapi.Headers("X-Preview", "1")).Get("/files/:name", emptyHandle, emptyHandle)
+	a.Get("/files/*path", emptyHandle)
+	a.When(zeroapi.Queries("format", "csv")).Post("/export", emptyHandle)
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest(zeroapi.MethodGet, "/files/a.txt", nil)
+	route, params := r.LookupRouteFor(req, zeroapi.MethodGet, "/files/a.txt")
+	if route == nil || len(route.Handlers()) != 1 || params["path"] != "a.txt" {
+		t.Fatal("failed predicate should fall back to wildcard")
+	}
+
+	req.Header.Set("X-Preview", "1")
+	if route, params = r.LookupRouteFor(req, zeroapi.MethodGet, "/files/a.txt"); route == nil || len(route.Handlers()) != 2 || params["name"] != "a.txt" {
+		t.Fatal("matched predicate should be used")
+	}
+
+	if err := r.Remove(zeroapi.MethodAny, "/export"); err != nil {
+		t.Fatal(err)
+	}
+	if r.Allowed("/export") != nil {
+		t.Fatal("remove any should remove conditional routes")
+	}
+}
+
+func TestForwardedSchemeInvalidProxy(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Fatal("invalid proxy should panic")
+		}
+	}()
+
+	zeroapi.ForwardedScheme([]string{"10.0.0.0/33"}, "https")
+}
@@ -1,6 +1,7 @@
 package router
 
 import (
+	"net/http"
 	"strings"
 
 	zeroapi "github.com/zerogo-hub/zero-api"
@@ -11,18 +12,34 @@ type Route interface {
 	// Insert 添加路由，路由不可重复
 	Insert(path string, handlers ...zeroapi.Handler)
 
+	// InsertWhen 添加带有匹配条件的路由，同一个路由可以添加多次
+	InsertWhen(path string, predicates []zeroapi.RoutePredicate, handlers ...zeroapi.Handler)
+
 	// Build 解析路由，包括动态参数，正则表达式，验证函数。路由优化
-	Build(router zeroapi.Router) bool
+	Build(router zeroapi.Router) error
 
 	// Lookup 查找路由
 	Lookup(path string) ([]zeroapi.Handler, map[string]string)
 
+	// LookupNode 查找路由，返回含有路由处理函数的节点
+	LookupNode(path string) (zeroapi.RouteNode, map[string]string)
+
+	// LookupParams 查找路由，返回含有路由处理函数的节点，动态参数依次添加到 params 末尾
+	LookupParams(path string, params *zeroapi.Params) zeroapi.RouteNode
+
+	// LookupRequest 与 LookupParams 相同，只返回能够处理 req 的节点，匹配条件都不满足时继续查找其它节点
+	// req 为 nil 时只返回含有没有匹配条件的路由的节点
+	LookupRequest(req *http.Request, path string, params *zeroapi.Params) zeroapi.RouteNode
+
 	// Child 查找节点信息
 	Child(path string) zeroapi.RouteNode
 
 	// Children 获取节点列表
 	Children() []zeroapi.RouteNode
 
+	// Root 获取根节点
+	Root() zeroapi.RouteNode
+
 	// Reset 重置，清理所有数据
 	Reset()
 }
@@ -31,6 +48,9 @@ type Route interface {
 type route struct {
 	// root 基数树根节点
 	root zeroapi.RouteNode
+
+	// statics 静态路由，路由全路径 -> 节点，在 Build 中生成，查找时优先使用，不需要遍历基数树
+	statics map[string]*routeNode
 }
 
 // NewRoute ..
@@ -42,18 +62,92 @@ func NewRoute() Route {
 func (re *route) Insert(path string, handlers ...zeroapi.Handler) {
 	paths := buildPath(path)
 	re.root.Put(path, paths, 0, handlers...)
+
+	// 需要重新 Build
+	re.statics = nil
 }
 
 // Build 解析路由，包括动态参数，正则表达式，验证函数
-func (re *route) Build(router zeroapi.Router) bool {
-	return re.root.Build(router)
+// 成功后生成静态路由表
+func (re *route) Build(router zeroapi.Router) error {
+	if err := re.root.Build(router); err != nil {
+		return err
+	}
+
+	re.buildStatics()
+
+	return nil
+}
+
+// buildStatics 生成静态路由表
+// 只保留使用路由全路径在基数树中查找时，匹配到自身并且没有动态参数的节点，保证与遍历基数树的结果一致
+func (re *route) buildStatics() {
+	statics := make(map[string]*routeNode)
+
+	var params zeroapi.Params
+
+	walk(re.root, func(node zeroapi.RouteNode) {
+		rn, ok := node.(*routeNode)
+		if !ok || !rn.IsHandler() || strings.ContainsAny(rn.fullPath, ":*") {
+			return
+		}
+
+		params = params[:0]
+		if re.root.LookupParams(rn.fullPath, &params) == rn && len(params) == 0 {
+			statics[rn.fullPath] = rn
+		}
+	})
+
+	re.statics = statics
 }
 
 // Lookup 查找路由
 func (re *route) Lookup(path string) ([]zeroapi.Handler, map[string]string) {
+	if rn, ok := re.statics[path]; ok {
+		return rn.handlers, nil
+	}
+
 	return re.root.Lookup(path, nil)
 }
 
+// LookupNode 查找路由，返回含有路由处理函数的节点
+func (re *route) LookupNode(path string) (zeroapi.RouteNode, map[string]string) {
+	if rn, ok := re.statics[path]; ok {
+		return rn, nil
+	}
+
+	return re.root.LookupNode(path, nil)
+}
+
+// LookupParams 查找路由，返回含有路由处理函数的节点，动态参数依次添加到 params 末尾
+// 优先使用静态路由表，未找到时遍历基数树
+func (re *route) LookupParams(path string, params *zeroapi.Params) zeroapi.RouteNode {
+	if rn, ok := re.statics[path]; ok {
+		return rn
+	}
+
+	return re.root.LookupParams(path, params)
+}
+
+// LookupRequest 与 LookupParams 相同，只返回能够处理 req 的节点，匹配条件都不满足时继续查找其它节点
+// req 为 nil 时只返回含有没有匹配条件的路由的节点
+func (re *route) LookupRequest(req *http.Request, path string, params *zeroapi.Params) zeroapi.RouteNode {
+	if node := re.lookup(path, params, lookupOption{checked: true, req: req}); node != nil {
+		return node
+	}
+
+	return nil
+}
+
+// lookup 按照 opt 查找路由，优先使用静态路由表
+func (re *route) lookup(path string, params *zeroapi.Params, opt lookupOption) *routeNode {
+	if rn, ok := re.statics[path]; ok && rn.accept(opt) {
+		return rn
+	}
+
+	return re.root.(*routeNode).lookup(path, params, opt)
+}
+
 // Child 查找节点信息
 func (re *route) Child(path string) zeroapi.RouteNode {
 	for _, child := range re.root.Children() {
@@ -74,9 +168,15 @@ func (re *route) Children() []zeroapi.RouteNode {
 	return re.root.Children()
 }
 
+// Root 获取根节点
+func (re *route) Root() zeroapi.RouteNode {
+	return re.root
+}
+
 // Reset 重置，清理所有数据
 func (re *route) Reset() {
 	re.root.Reset()
+	re.statics = nil
 }
 
 func buildPath(path string) []string {
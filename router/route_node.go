@@ -1,7 +1,10 @@
 package router
 
 import (
+	"fmt"
+	"net/http"
 	"regexp"
+	"sort"
 	"strings"
 
 	zeroapi "github.com/zerogo-hub/zero-api"
@@ -13,6 +16,9 @@ const (
 
 	// WildcardCharacter 通配符，比如 /blog/hi/*
 	WildcardCharacter = '*'
+
+	// NotEmptyCharacter 通配符匹配的内容不可以为空，比如 /files/*filepath+
+	NotEmptyCharacter = '+'
 )
 
 const (
@@ -30,6 +36,12 @@ const (
 
 	// VALIDATOR 是否含有验证函数
 	VALIDATOR = 2 << 3
+
+	// MULTIPLE 一个节点中含有多个动态参数，或者动态参数与字面量混合，比如 /:name.:ext
+	MULTIPLE = 2 << 4
+
+	// OPTIONAL 可选的动态参数，比如 /:month?，/:page?=1
+	OPTIONAL = 2 << 5
 )
 
 // routeNode 一颗基数树的一个节点
@@ -59,6 +71,24 @@ type routeNode struct {
 	// pattern 编译好的正则表达式
 	pattern *regexp.Regexp
 
+	// captures 正则表达式中含有命名分组，例如 (?P<year>\d{4})，匹配成功后命名分组的值也作为动态参数
+	captures bool
+
+	// parts 含有多个部分的节点，按顺序存储字面量和动态参数，见 MULTIPLE
+	parts []segmentPart
+
+	// defaultValue 可选参数的默认值，见 OPTIONAL
+	defaultValue string
+
+	// notEmpty 通配符匹配的内容不可以为空
+	notEmpty bool
+
+	// route 匹配成功时返回的路由信息，包括元数据，在 Router.Build 中生成
+	route *matchedRoute
+
+	// variants 带有匹配条件的路由处理函数，按照注册顺序检查，见 App.When
+	variants []*routeVariant
+
 	// children 子节点
 	children []zeroapi.RouteNode
 }
@@ -71,23 +101,35 @@ func (rn *routeNode) Put(fullPath string, paths []string, height int, handlers .
 		return
 	}
 
+	rn.put(fullPath, paths, height).handlers = handlersWithoutNil(handlers...)
+}
+
+// put 查找路由的最终节点，不存在时创建
+func (rn *routeNode) put(fullPath string, paths []string, height int) *routeNode {
 	if len(paths) == height || rn.IsWildcard() {
 		// 本次路由的最终节点
 		rn.fullPath = fullPath
-		rn.handlers = handlersWithoutNil(handlers...)
-		return
+		return rn
 	}
 
-	path := paths[height]
+	// 可选参数，/:page?=1 -> /:page
+	path, isOptional, defaultValue := parseOptional(paths[height])
 
 	// 是否已存在对应的子节点
-	child := rn.child(path)
+	child := rn.child(segmentPath(path))
 	if child == nil {
 		child = newChild(path)
 		rn.children = append(rn.children, child)
 	}
 
-	child.Put(fullPath, paths, height+1, handlers...)
+	if isOptional {
+		if node, ok := child.(*routeNode); ok {
+			node.flag |= OPTIONAL
+			node.defaultValue = defaultValue
+		}
+	}
+
+	return child.(*routeNode).put(fullPath, paths, height+1)
 }
 
 func handlersWithoutNil(handlers ...zeroapi.Handler) []zeroapi.Handler {
@@ -107,22 +149,57 @@ func newChild(path string) zeroapi.RouteNode {
 	flag := STATIC
 
 	if len(path) > 1 {
-		if path[1] == DynamicCharacter {
-			flag |= DYNAMIC
-		} else if path[1] == WildcardCharacter {
+		if path[1] == WildcardCharacter {
 			flag |= WILDCARD
+		} else if isDynamicSegment(path) {
+			// /:id，/v:major(^\d+$).:minor
+			flag |= DYNAMIC
 		}
 	}
 
-	child := &routeNode{path: path, flag: flag}
+	child := &routeNode{path: segmentPath(path), flag: flag}
 
 	return child
 }
 
+// segmentPath 节点使用的路径，静态片段中的 "\:" 还原为 ":"，例如 /users\:batchGet -> /users:batchGet
+func segmentPath(path string) string {
+	if len(path) < 2 || path[1] == WildcardCharacter || isDynamicSegment(path) {
+		return path
+	}
+
+	return unescapeColon(path)
+}
+
+// parseOptional 解析可选参数
+// 示例:
+// /:month? -> /:month, true, ""
+// /:page(^\d+$)?=1 -> /:page(^\d+$), true, "1"
+func parseOptional(path string) (string, bool, string) {
+	if len(path) < 2 || path[1] != DynamicCharacter {
+		return path, false, ""
+	}
+
+	tokens, err := splitSegment(path[1:])
+	if err != nil || len(tokens) != 2 || tokens[1][0] != '?' {
+		return path, false, ""
+	}
+
+	defaultValue := tokens[1][1:]
+	if defaultValue != "" {
+		if defaultValue[0] != '=' {
+			return path, false, ""
+		}
+		defaultValue = defaultValue[1:]
+	}
+
+	return "/" + tokens[0], true, defaultValue
+}
+
 // child 在子节点中查找已存在的节点
 func (rn *routeNode) child(path string) zeroapi.RouteNode {
 	for _, child := range rn.children {
-		if child.Path() == path || child.IsWildcard() {
+		if child.Path() == path {
 			return child
 		}
 	}
@@ -131,14 +208,47 @@ func (rn *routeNode) child(path string) zeroapi.RouteNode {
 }
 
 // Build 解析路由，包括动态参数，正则表达式，验证函数。路由优化
-func (rn *routeNode) Build(router zeroapi.Router) bool {
+// 失败时返回 *zeroapi.RouteError，其中 Path 为使用该节点的任意一个路由
+func (rn *routeNode) Build(router zeroapi.Router) error {
 	if rn.IsWildcard() {
-		return true
+		if err := rn.parseParam(router); err != nil {
+			return rn.buildError(err)
+		}
+
+		rn.countDynamicNum()
+
+		return nil
 	}
 
 	if rn.IsDynamic() {
-		if !(rn.parseRegexp() && rn.parseValidator(router) && rn.parseDynamic()) {
-			return false
+		tokens, err := splitSegment(rn.path[1:])
+		if err != nil {
+			return rn.buildError(err)
+		}
+
+		if len(tokens) > 1 {
+			err = rn.parseMultiple(tokens, router)
+		} else {
+			err = rn.parseParam(router)
+		}
+		if err != nil {
+			return rn.buildError(err)
+		}
+	}
+
+	// 可选参数之后只能是可选参数
+	if rn.IsOptional() {
+		if rn.IsMultiple() {
+			return rn.buildError(fmt.Errorf("%w: optional param cannot be mixed with literals", zeroapi.ErrInvalidSegment))
+		}
+
+		for _, child := range rn.children {
+			if !child.IsOptional() {
+				if node, ok := child.(*routeNode); ok {
+					return node.buildError(fmt.Errorf("%w: required segment after optional param", zeroapi.ErrInvalidSegment))
+				}
+				return rn.buildError(zeroapi.ErrInvalidSegment)
+			}
 		}
 	}
 
@@ -146,76 +256,126 @@ func (rn *routeNode) Build(router zeroapi.Router) bool {
 
 	// 解析子节点
 	for _, child := range rn.children {
-		if !child.Build(router) {
-			return false
+		if err := child.Build(router); err != nil {
+			return err
 		}
 	}
 
+	rn.sortChildren()
 	rn.countDynamicNum()
 
-	return true
+	return nil
+}
+
+// buildError 生成解析错误，Segment 为当前节点的 path
+func (rn *routeNode) buildError(err error) error {
+	e := &zeroapi.RouteError{Segment: rn.path, Err: err}
+
+	walk(rn, func(node zeroapi.RouteNode) {
+		if e.Path == "" && node.IsHandler() {
+			e.Path = node.FullPath()
+		}
+	})
+
+	return e
+}
+
+// parseParam 依次解析正则表达式，验证函数，动态参数名称
+func (rn *routeNode) parseParam(router zeroapi.Router) error {
+	if err := rn.parseRegexp(); err != nil {
+		return err
+	}
+
+	if err := rn.parseValidator(router); err != nil {
+		return err
+	}
+
+	return rn.parseDynamic()
 }
 
 // parseRegexp 解析当前节点 path 上的正则表达式
 //
-// 一个节点只包含一个正则表达式
-func (rn *routeNode) parseRegexp() bool {
+// 一个节点只包含一个正则表达式，紧跟在参数名称之后
+func (rn *routeNode) parseRegexp() error {
 	// 示例: /blog/list/:id(^\d+$)
-	pos := strings.Index(rn.path, "(")
+	pos := rn.paramEnd()
 
-	if pos == -1 {
-		return true
+	if pos >= len(rn.path) || rn.path[pos] != '(' {
+		return nil
 	}
 
-	posEnd := strings.Index(rn.path, ")")
+	posEnd := closeParen(rn.path, pos)
 	if posEnd == -1 {
-		// 缺失右括号
-		return false
+		return fmt.Errorf("%w: missing \")\"", zeroapi.ErrUnbalanced)
 	}
 	if pos+1 >= posEnd {
-		// )(
-		return false
+		return fmt.Errorf("%w: empty regexp", zeroapi.ErrInvalidRegexp)
 	}
 
-	regexpExpress := rn.path[pos+1 : posEnd]
-	rn.pattern = regexp.MustCompile(regexpExpress)
+	pattern, err := regexp.Compile(rn.path[pos+1 : posEnd])
+	if err != nil {
+		return fmt.Errorf("%w: %s", zeroapi.ErrInvalidRegexp, err.Error())
+	}
+
+	rn.pattern = pattern
 	rn.flag |= REGEXP
 
-	return true
+	for _, name := range pattern.SubexpNames() {
+		if name != "" {
+			rn.captures = true
+			break
+		}
+	}
+
+	return nil
 }
 
 // parseValidator 解析当前节点 path 上的验证函数
 //
-// 验证函数必须现在 Router 中注册
-func (rn *routeNode) parseValidator(router zeroapi.Router) bool {
-	if router == nil {
-		return true
+// 验证函数必须先在 Router 中注册，带参数的验证函数例如 len(3,16) 必须先注册生成函数
+// router 为 nil 时只检查格式
+func (rn *routeNode) parseValidator(router zeroapi.Router) error {
+	// 示例: /blog/list/:id(^\d+$)|isNum|less4|
+	// 跳过正则表达式，正则表达式中可能含有 |
+	pos := rn.paramEnd()
+	if pos < len(rn.path) && rn.path[pos] == '(' {
+		if posEnd := closeParen(rn.path, pos); posEnd != -1 {
+			pos = posEnd + 1
+		}
 	}
 
-	// 示例: /blog/list/:id|isNum|less4|
-	pos := strings.Index(rn.path, "|")
+	if pos >= len(rn.path) {
+		return nil
+	}
 
-	if pos == -1 {
-		return true
+	if rn.path[pos] != '|' {
+		return fmt.Errorf("%w: unexpected \"%s\"", zeroapi.ErrInvalidSegment, rn.path[pos:])
 	}
 
 	posEnd := strings.LastIndex(rn.path, "|")
-	if posEnd == -1 || pos == posEnd {
+	if pos == posEnd {
 		// 必须包含在 |...| 中间
-		return false
+		return fmt.Errorf("%w: missing \"|\"", zeroapi.ErrUnbalanced)
 	}
 
 	handlerNames := strings.Split(rn.path[pos+1:posEnd], "|")
-	if len(handlerNames) == 0 || handlerNames[0] == "" {
-		return false
+	for _, handlerName := range handlerNames {
+		if handlerName == "" {
+			return fmt.Errorf("%w: empty validator name", zeroapi.ErrInvalidSegment)
+		}
+	}
+
+	// 只检查格式
+	if router == nil {
+		return nil
 	}
 
 	rn.validators = make([]zeroapi.RouterValidator, 0, len(handlerNames))
 
 	for _, handlerName := range handlerNames {
-		handler := router.Validator(handlerName)
-		if handler == nil {
-			return false
+		handler, err := resolveValidator(router, handlerName)
+		if err != nil {
+			return err
 		}
 
 		rn.validators = append(rn.validators, handler)
@@ -223,29 +383,70 @@ func (rn *routeNode) parseValidator(router zeroapi.Router) bool {
 
 	rn.flag |= VALIDATOR
 
-	return true
+	return nil
 }
 
 // parseDynamic 解析当前节点 path 上的动态参数
-func (rn *routeNode) parseDynamic() bool {
+func (rn *routeNode) parseDynamic() error {
 	// 示例: /blog/article/:id(^\d+$)|less4|/del
 
 	// 当前节点的 path = /:id(^\d+$)|less4|
 	// rn.dynamicName = id
+	rn.dynamicName = rn.path[2:rn.nameEnd()]
+
+	if rn.IsWildcard() {
+		// /*，/*filepath，/*filepath+
+		if rn.dynamicName == "" {
+			rn.dynamicName = string(WildcardCharacter)
+		}
+		rn.notEmpty = rn.paramEnd() != rn.nameEnd()
+	}
+
+	if rn.dynamicName == "" {
+		return fmt.Errorf("%w: missing param name", zeroapi.ErrInvalidSegment)
+	}
+
+	return nil
+}
+
+// dynamicNames 当前节点所有动态参数的名称
+func (rn *routeNode) dynamicNames() []string {
+	if !rn.IsMultiple() {
+		return []string{rn.dynamicName}
+	}
 
+	names := make([]string, 0, len(rn.parts))
+	for _, part := range rn.parts {
+		if part.node != nil {
+			names = append(names, part.node.dynamicName)
+		}
+	}
+
+	return names
+}
+
+// paramEnd 动态参数名称以及修饰符结束的位置，之后是正则表达式和验证函数
+func (rn *routeNode) paramEnd() int {
+	pos := rn.nameEnd()
+
+	if rn.IsWildcard() && pos < len(rn.path) && rn.path[pos] == NotEmptyCharacter {
+		pos++
+	}
+
+	return pos
+}
+
+// nameEnd 动态参数名称结束的位置
+func (rn *routeNode) nameEnd() int {
 	// 开头两个符号为 /:，所以从 2 开始
 	i := 2
 	for ; i < len(rn.path); i++ {
-		c := rn.path[i]
-
-		if c == '|' || c == '(' {
+		if !isNameChar(rn.path[i]) {
 			break
 		}
 	}
 
-	rn.dynamicName = rn.path[2:i]
-
-	return true
+	return i
 }
 
 // merge 路由合并，如果只有一个子节点，且子节点是 STATIC 的，则合并
@@ -270,10 +471,38 @@ func (rn *routeNode) merge() {
 	rn.flag |= child.Flag()
 	rn.children = child.Children()
 	rn.handlers = child.Handlers()
+	rn.fullPath = child.FullPath()
+	if node, ok := child.(*routeNode); ok {
+		rn.variants = node.variants
+	}
 
 	rn.merge()
 }
 
+// sortChildren 按照匹配优先级对子节点排序，优先级相同的保持注册顺序
+// 静态路由 > 带正则表达式，验证函数或者字面量的动态路由 > 动态路由 > 带正则表达式或者验证函数的通配符 > 通配符
+func (rn *routeNode) sortChildren() {
+	sort.SliceStable(rn.children, func(i, j int) bool {
+		return priority(rn.children[i]) < priority(rn.children[j])
+	})
+}
+
+// priority 节点匹配优先级，值越小越优先
+func priority(node zeroapi.RouteNode) int {
+	switch {
+	case node.IsWildcard() && (node.IsRegexp() || node.IsValidator()):
+		return 3
+	case node.IsWildcard():
+		return 4
+	case node.IsMultiple(), node.IsDynamic() && (node.IsRegexp() || node.IsValidator()):
+		return 1
+	case node.IsDynamic():
+		return 2
+	}
+
+	return 0
+}
+
 func (rn *routeNode) countDynamicNum() {
 
 	dynamicNum := 0
@@ -285,7 +514,13 @@ func (rn *routeNode) countDynamicNum() {
 		}
 	}
 
-	if rn.IsDynamic() {
+	if rn.IsMultiple() {
+		for _, part := range rn.parts {
+			if part.node != nil {
+				dynamicNum++
+			}
+		}
+	} else if rn.IsDynamic() || rn.IsWildcard() {
 		dynamicNum++
 	}
 
@@ -293,60 +528,140 @@ func (rn *routeNode) countDynamicNum() {
 }
 
 func (rn *routeNode) Lookup(path string, dynamic map[string]string) ([]zeroapi.Handler, map[string]string) {
+	if node, dynamic := rn.LookupNode(path, dynamic); node != nil {
+		return node.Handlers(), dynamic
+	}
+
+	return nil, nil
+}
+
+// LookupNode 查找路由，返回含有路由处理函数的节点
+// 动态参数添加到 dynamic 中，dynamic 为 nil 且有动态参数时创建新的 map，见 LookupParams
+func (rn *routeNode) LookupNode(path string, dynamic map[string]string) (zeroapi.RouteNode, map[string]string) {
+	var params zeroapi.Params
+
+	node := rn.lookup(path, &params, lookupOption{})
+	if node == nil {
+		return nil, nil
+	}
+
+	if dynamic == nil {
+		return node, params.Map()
+	}
+
+	for _, p := range params {
+		dynamic[p.Key] = p.Value
+	}
+
+	return node, dynamic
+}
+
+// LookupParams 查找路由，返回含有路由处理函数的节点，动态参数依次添加到 params 末尾
+// 未匹配时 params 保持不变，params 可以复用，不需要分配内存
+func (rn *routeNode) LookupParams(path string, params *zeroapi.Params) zeroapi.RouteNode {
+	if node := rn.lookup(path, params, lookupOption{}); node != nil {
+		return node
+	}
+
+	return nil
+}
+
+// lookupOption 查找路由时的选项，零值表示含有任意路由处理函数的节点都可以使用
+type lookupOption struct {
+	// checked 为 true 时，只使用没有匹配条件的路由，或者匹配条件满足 req 的路由，见 accept
+	checked bool
+
+	// req 用于检查匹配条件，为 nil 时只使用没有匹配条件的路由
+	req *http.Request
+
+	// unescape 为 true 时，path 为未解码的路径，动态参数的值先解码，再检查正则表达式和验证函数，通配符除外
+	unescape bool
+}
+
+// accept 节点是否可以作为查找结果，不可以时继续查找其它节点
+func (rn *routeNode) accept(opt lookupOption) bool {
+	if !opt.checked {
+		return rn.IsHandler()
+	}
+
+	if len(rn.handlers) > 0 {
+		return true
+	}
+
+	if opt.req != nil {
+		for _, v := range rn.variants {
+			if v.match(opt.req) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// lookup 查找路由，失败时将 params 还原到查找前的长度
+func (rn *routeNode) lookup(path string, params *zeroapi.Params, opt lookupOption) *routeNode {
 
 	if rn.IsWildcard() {
-		return rn.handlers, dynamic
+		return rn.lookupByWildcard(path, params, opt)
 	}
 
 	if rn.IsDynamic() {
-		return rn.lookupByDynamic(path, dynamic)
+		return rn.lookupByDynamic(path, params, opt)
 	}
 
-	return rn.lookupByStatic(path, dynamic)
+	return rn.lookupByStatic(path, params, opt)
 }
 
-func (rn *routeNode) lookupByStatic(path string, dynamic map[string]string) ([]zeroapi.Handler, map[string]string) {
+// lookupChildren 依次在子节点中查找
+func (rn *routeNode) lookupChildren(path string, params *zeroapi.Params, opt lookupOption) *routeNode {
+	for _, child := range rn.children {
+		if node, ok := child.(*routeNode); ok {
+			if found := node.lookup(path, params, opt); found != nil {
+				return found
+			}
+		}
+	}
+
+	return nil
+}
+
+func (rn *routeNode) lookupByStatic(path string, params *zeroapi.Params, opt lookupOption) *routeNode {
 	if rn.path == path {
-		return rn.handlers, dynamic
+		if rn.accept(opt) {
+			return rn
+		}
+
+		return rn.lookupOptional(params, opt)
 	}
 
 	// rn.path = /users，path = /user
 	// 当前节点 rn 不匹配 path
 	if len(rn.path) >= len(path) {
-		return nil, nil
+		return nil
 	}
 
 	// rn.path = /abc, path = /xyz/v1
 	parentPath := path[0:len(rn.path)]
 	if parentPath != rn.path {
-		return nil, nil
+		return nil
 	}
 
 	// rn.path = /user，path = /user/add
 	// 从子节点中匹配，childPath = /add
 	childPath := path[len(rn.path):]
 	if childPath[0] != '/' {
-		return nil, nil
-	}
-
-	for _, child := range rn.children {
-		if handlers, dynamic := child.Lookup(childPath, dynamic); handlers != nil {
-			return handlers, dynamic
-		}
+		return nil
 	}
 
-	return nil, nil
+	return rn.lookupChildren(childPath, params, opt)
 }
 
-func (rn *routeNode) lookupByDynamic(path string, dynamic map[string]string) ([]zeroapi.Handler, map[string]string) {
+func (rn *routeNode) lookupByDynamic(path string, params *zeroapi.Params, opt lookupOption) *routeNode {
 
 	// rn.path = /:id，path = /1001/add
-	if dynamic == nil {
-		dynamic = make(map[string]string, rn.dynamicNum)
-	}
-
 	// 获取 id 值，id = 1001
-	pos := strings.Index(path[1:], "/")
+	pos := strings.IndexByte(path[1:], '/')
 	dynamicValueEnd := pos
 	if pos < 0 {
 		// rn.path = /:id, path = /1001
@@ -354,28 +669,156 @@ func (rn *routeNode) lookupByDynamic(path string, dynamic map[string]string) ([]
 	}
 	dynamicValue := path[1 : dynamicValueEnd+1]
 
-	if !rn.checkDynamicValueValid(dynamicValue) {
-		return nil, nil
+	// path = //add，动态参数值不可以为空
+	if dynamicValue == "" {
+		return nil
 	}
 
-	// rn.dynamicName = id
-	dynamic[rn.dynamicName] = dynamicValue
+	// 子节点匹配失败时需要还原，避免影响其它节点的匹配
+	n := len(*params)
+
+	if !rn.matchDynamicValue(dynamicValue, params, opt) {
+		return nil
+	}
 
 	// 如果 path[1:] 没有 '/' 或者 '/' 在最后一个，表示该节点是最后一个节点了
 	if pos == -1 || pos == len(path)-1 {
-		return rn.handlers, dynamic
+		if rn.accept(opt) {
+			return rn
+		}
+
+		if node := rn.lookupOptional(params, opt); node != nil {
+			return node
+		}
+	} else {
+		// 在子节点查找
+		if node := rn.lookupChildren(path[pos+1:], params, opt); node != nil {
+			return node
+		}
 	}
 
-	// 在子节点查找
-	childPath := path[pos+1:]
+	*params = (*params)[:n]
 
+	return nil
+}
+
+// matchDynamicValue 检查动态参数值，成功时将各个动态参数的值添加到 params 末尾
+func (rn *routeNode) matchDynamicValue(dynamicValue string, params *zeroapi.Params, opt lookupOption) bool {
+	if opt.unescape {
+		dynamicValue = unescapeValue(dynamicValue)
+	}
+
+	if rn.IsMultiple() {
+		return matchParts(rn.parts, dynamicValue, params)
+	}
+
+	return rn.appendValue(dynamicValue, params)
+}
+
+// appendValue 检查动态参数值，成功时将参数值，以及正则表达式中命名分组的值依次添加到 params 末尾
+func (rn *routeNode) appendValue(value string, params *zeroapi.Params) bool {
+	if !rn.captures {
+		if !rn.checkDynamicValueValid(value) {
+			return false
+		}
+
+		*params = append(*params, zeroapi.Param{Key: rn.dynamicName, Value: value})
+
+		return true
+	}
+
+	if rn.IsValidator() && !rn.checkValidator(value) {
+		return false
+	}
+
+	// 只有含有命名分组时才需要获取分组的位置，会分配内存
+	match := rn.pattern.FindStringSubmatchIndex(value)
+	if match == nil {
+		return false
+	}
+
+	*params = append(*params, zeroapi.Param{Key: rn.dynamicName, Value: value})
+
+	for i, name := range rn.pattern.SubexpNames() {
+		if name == "" || match[2*i] < 0 {
+			continue
+		}
+
+		*params = append(*params, zeroapi.Param{Key: name, Value: value[match[2*i]:match[2*i+1]]})
+	}
+
+	return true
+}
+
+// captureNum 匹配成功后添加的参数数量，参数值 + 命名分组
+func (rn *routeNode) captureNum() int {
+	num := 1
+
+	if rn.captures {
+		for _, name := range rn.pattern.SubexpNames() {
+			if name != "" {
+				num++
+			}
+		}
+	}
+
+	return num
+}
+
+// lookupOptional 路径已经匹配完毕，但当前节点没有处理函数
+// 尝试使用可选参数子节点的处理函数，并填充可选参数的默认值
+func (rn *routeNode) lookupOptional(params *zeroapi.Params, opt lookupOption) *routeNode {
 	for _, child := range rn.children {
-		if handlers, dynamic := child.Lookup(childPath, dynamic); handlers != nil {
-			return handlers, dynamic
+		node, ok := child.(*routeNode)
+		if !ok || !node.IsOptional() {
+			continue
 		}
+
+		n := len(*params)
+
+		// 没有默认值时参数值为空，参数仍然存在
+		*params = append(*params, zeroapi.Param{Key: node.dynamicName, Value: node.defaultValue})
+
+		found := node
+		if !node.accept(opt) {
+			// /:year?/:month?，继续查找下一个可选参数
+			if found = node.lookupOptional(params, opt); found == nil {
+				*params = (*params)[:n]
+				continue
+			}
+		}
+
+		return found
 	}
 
-	return nil, nil
+	return nil
+}
+
+// lookupByWildcard 通配符匹配剩余的全部路径
+// 结果存储在 params 中，未命名的通配符的参数名为 "*"
+func (rn *routeNode) lookupByWildcard(path string, params *zeroapi.Params, opt lookupOption) *routeNode {
+	if !rn.accept(opt) {
+		return nil
+	}
+
+	// rn.path = /*filepath，path = /css/a.css
+	value := path[1:]
+
+	if value == "" {
+		if rn.notEmpty {
+			return nil
+		}
+
+		*params = append(*params, zeroapi.Param{Key: rn.dynamicName, Value: value})
+
+		return rn
+	}
+
+	if !rn.appendValue(value, params) {
+		return nil
+	}
+
+	return rn
 }
 
 func (rn *routeNode) checkDynamicValueValid(dynamicValue string) bool {
@@ -410,6 +853,11 @@ func (rn *routeNode) Path() string {
 	return rn.path
 }
 
+// FullPath 获取路由全路径，只有含有路由处理函数的节点才有值
+func (rn *routeNode) FullPath() string {
+	return rn.fullPath
+}
+
 // Child 查找节点信息
 func (rn *routeNode) Child(path string) zeroapi.RouteNode {
 	for _, child := range rn.children {
@@ -441,6 +889,12 @@ func (rn *routeNode) Reset() {
 	rn.dynamicName = ""
 	rn.dynamicNum = 0
 	rn.pattern = nil
+	rn.captures = false
+	rn.parts = nil
+	rn.defaultValue = ""
+	rn.notEmpty = false
+	rn.route = nil
+	rn.variants = nil
 	rn.children = nil
 }
 
@@ -469,9 +923,19 @@ func (rn *routeNode) IsValidator() bool {
 	return rn.flag&VALIDATOR != 0
 }
 
-// IsHandler 是否有路由处理函数或者中间件
+// IsMultiple 一个节点中含有多个动态参数，或者动态参数与字面量混合
+func (rn *routeNode) IsMultiple() bool {
+	return rn.flag&MULTIPLE != 0
+}
+
+// IsOptional 可选的动态参数
+func (rn *routeNode) IsOptional() bool {
+	return rn.flag&OPTIONAL != 0
+}
+
+// IsHandler 是否有路由处理函数或者中间件，包括带有匹配条件的路由处理函数
 func (rn *routeNode) IsHandler() bool {
-	return rn.handlers != nil && len(rn.handlers) > 0
+	return len(rn.handlers) > 0 || len(rn.variants) > 0
 }
 
 // Flag 获取标记
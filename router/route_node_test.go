@@ -220,21 +220,21 @@ func TestRouteDynamicParseRegexp(t *testing.T) {
 
 	// 缺失右括号
 	route.Insert("/blog/list/:id(^\\d+$", emptyHandle)
-	if route.Build(nil) {
+	if route.Build(nil) == nil {
 		t.Fatal("miss )")
 	}
 
 	// 左右括号对调
 	route.Reset()
 	route.Insert("/blog/list/:id)^\\d+$(", emptyHandle)
-	if route.Build(nil) {
+	if route.Build(nil) == nil {
 		t.Fatal("invalid )(")
 	}
 
 	// 正常
 	route.Reset()
 	route.Insert("/blog/list/:id(^\\d+$)", emptyHandle)
-	if !route.Build(nil) {
+	if err := route.Build(nil); err != nil {
 		t.Fatal("invalid regexp")
 	}
 }
@@ -258,35 +258,35 @@ func TestRouteDynamicParseValidator(t *testing.T) {
 
 	// 没有验证函数
 	route.Insert("/blog/list/:id", emptyHandle)
-	if !route.Build(r) {
+	if err := route.Build(r); err != nil {
 		t.Fatal("no validator")
 	}
 
 	// 缺少 | 将验证函数包裹
 	route.Reset()
 	route.Insert("/blog/list/:id|isNum", emptyHandle)
-	if route.Build(r) {
+	if route.Build(r) == nil {
 		t.Fatal("miss \"|\"")
 	}
 
 	// 缺少验证函数
 	route.Reset()
 	route.Insert("/blog/list/:id||", emptyHandle)
-	if route.Build(r) {
+	if route.Build(r) == nil {
 		t.Fatal("miss validator")
 	}
 
 	// 不存在的验证函数
 	route.Reset()
 	route.Insert("/blog/list/:id|isNum|less4|", emptyHandle)
-	if route.Build(r) {
+	if route.Build(r) == nil {
 		t.Fatal("validator not found")
 	}
 
 	// 正常路由
 	route.Reset()
 	route.Insert("/blog/list/:id|isNum|", emptyHandle)
-	if !route.Build(r) {
+	if err := route.Build(r); err != nil {
 		t.Fatal("failed")
 	}
 }
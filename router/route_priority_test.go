@@ -0,0 +1,187 @@
+package router_test
+
+import (
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+	zerorouter "github.com/zerogo-hub/zero-api/router"
+)
+
+// matched 返回命中的路由名称
+func matched(handlers []zeroapi.Handler) string {
+	if len(handlers) == 0 {
+		return ""
+	}
+
+	var name string
+	ctx := &nameContext{name: &name}
+	handlers[0](ctx)
+
+	return name
+}
+
+type nameContext struct {
+	zeroapi.Context
+	name *string
+}
+
+func named(name string) zeroapi.Handler {
+	return func(ctx zeroapi.Context) {
+		*ctx.(*nameContext).name = name
+	}
+}
+
+func TestRoutePriorityStaticFirst(t *testing.T) {
+	route := zerorouter.NewRoute()
+
+	// 注册顺序不影响匹配结果
+	route.Insert("/user/:id", named("dynamic"))
+	route.Insert("/user/me", named("static"))
+	route.Build(nil)
+
+	if handlers, _ := route.Lookup("/user/me"); matched(handlers) != "static" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+
+	if handlers, dynamic := route.Lookup("/user/1001"); matched(handlers) != "dynamic" || dynamic["id"] != "1001" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+}
+
+func TestRoutePriorityRegexpFirst(t *testing.T) {
+	route := zerorouter.NewRoute()
+
+	route.Insert("/user/:name", named("plain"))
+	route.Insert("/user/:id(^\\d+$)", named("regexp"))
+	route.Build(nil)
+
+	if handlers, dynamic := route.Lookup("/user/1001"); matched(handlers) != "regexp" || dynamic["id"] != "1001" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+
+	if handlers, dynamic := route.Lookup("/user/yaha"); matched(handlers) != "plain" || dynamic["name"] != "yaha" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+}
+
+func TestRoutePriorityValidatorFirst(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+	r.RegisterRouterValidator("isNum", isNum)
+
+	r.Register(zeroapi.MethodGet, "/user/:name", named("plain"))
+	r.Register(zeroapi.MethodGet, "/user/:id|isNum|", named("validator"))
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/user/1001"); matched(handlers) != "validator" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+}
+
+func TestRoutePriorityWildcardLast(t *testing.T) {
+	route := zerorouter.NewRoute()
+
+	route.Insert("/*", named("wildcard"))
+	route.Insert("/user", named("static"))
+	route.Insert("/:name", named("dynamic"))
+	route.Build(nil)
+
+	if handlers, _ := route.Lookup("/user"); matched(handlers) != "static" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+
+	if handlers, _ := route.Lookup("/blog"); matched(handlers) != "dynamic" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+
+	if handlers, dynamic := route.Lookup("/blog/1001"); matched(handlers) != "wildcard" || dynamic["*"] != "blog/1001" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+}
+
+func TestRoutePriorityBacktrack(t *testing.T) {
+	route := zerorouter.NewRoute()
+
+	// /user/me 存在，但是 /user/me/books 只能由 /user/:id/books 匹配
+	route.Insert("/user/me/profile", named("profile"))
+	route.Insert("/user/:id/books", named("books"))
+	route.Build(nil)
+
+	if handlers, dynamic := route.Lookup("/user/me/books"); matched(handlers) != "books" || dynamic["id"] != "me" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+
+	if handlers, _ := route.Lookup("/user/me/profile"); matched(handlers) != "profile" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+}
+
+func TestRoutePriorityBacktrackDynamic(t *testing.T) {
+	route := zerorouter.NewRoute()
+
+	// 匹配失败的分支不能留下动态参数
+	route.Insert("/blog/:id(^\\d+$)/name", named("name"))
+	route.Insert("/blog/:title/:page", named("page"))
+	route.Build(nil)
+
+	handlers, dynamic := route.Lookup("/blog/1001/2")
+	if matched(handlers) != "page" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+
+	if len(dynamic) != 2 || dynamic["title"] != "1001" || dynamic["page"] != "2" {
+		t.Fatalf("invalid dynamic: %v", dynamic)
+	}
+
+	if _, exist := dynamic["id"]; exist {
+		t.Fatal("dynamic id should be removed")
+	}
+}
+
+func TestRoutePriorityBacktrackWildcard(t *testing.T) {
+	route := zerorouter.NewRoute()
+
+	route.Insert("/files/:name/raw", named("raw"))
+	route.Insert("/files/*", named("wildcard"))
+	route.Build(nil)
+
+	if handlers, dynamic := route.Lookup("/files/a/raw"); matched(handlers) != "raw" || dynamic["name"] != "a" {
+		t.Fatalf("invalid match: %s", matched(handlers))
+	}
+
+	handlers, dynamic := route.Lookup("/files/a/b/c")
+	if matched(handlers) != "wildcard" || dynamic["*"] != "a/b/c" {
+		t.Fatalf("invalid match: %s, %v", matched(handlers), dynamic)
+	}
+
+	if _, exist := dynamic["name"]; exist {
+		t.Fatal("dynamic name should be removed")
+	}
+}
+
+func TestRoutePriorityChildrenOrder(t *testing.T) {
+	route := zerorouter.NewRoute()
+
+	route.Insert("/api/*", emptyHandle)
+	route.Insert("/api/:name", emptyHandle)
+	route.Insert("/api/:id(^\\d+$)", emptyHandle)
+	route.Insert("/api/user", emptyHandle)
+	route.Build(nil)
+
+	children := route.Child("/api").Children()
+	expected := []string{"/user", "/:id(^\\d+$)", "/:name", "/*"}
+
+	if len(children) != len(expected) {
+		t.Fatalf("invalid children: %d", len(children))
+	}
+
+	for i, child := range children {
+		if child.Path() != expected[i] {
+			t.Fatalf("invalid order, index: %d, expected: %s, got: %s", i, expected[i], child.Path())
+		}
+	}
+}
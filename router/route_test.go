@@ -99,3 +99,239 @@ func TestRouteLookupDynamicWildcard(t *testing.T) {
 		t.Fatal("invalid 1")
 	}
 }
+
+func TestRouteLookupMultiple(t *testing.T) {
+	route := zerorouter.NewRoute()
+	route.Insert("/files/:name.:ext", emptyHandle)
+	route.Insert("/:version(^v\\d+$)/users", emptyHandle)
+	route.Insert("/api/v:major.:minor/users", emptyHandle)
+	route.Insert("/img/:id(^\\d+$)-thumb.png", emptyHandle)
+	if err := route.Build(nil); err != nil {
+		t.Fatal("build failed")
+	}
+
+	tests := []struct {
+		path     string
+		expected map[string]string
+	}{
+		{"/files/a.txt", map[string]string{"name": "a", "ext": "txt"}},
+		{"/files/a.tar.gz", map[string]string{"name": "a.tar", "ext": "gz"}},
+		{"/api/v1.2/users", map[string]string{"major": "1", "minor": "2"}},
+		{"/img/1001-thumb.png", map[string]string{"id": "1001"}},
+	}
+
+	for _, test := range tests {
+		handlers, dynamic := route.Lookup(test.path)
+		if handlers == nil {
+			t.Fatalf("%s: not found", test.path)
+		}
+
+		if len(dynamic) != len(test.expected) {
+			t.Fatalf("%s: invalid dynamic: %v", test.path, dynamic)
+		}
+
+		for key, value := range test.expected {
+			if dynamic[key] != value {
+				t.Fatalf("%s: invalid dynamic: %v", test.path, dynamic)
+			}
+		}
+	}
+
+	for _, path := range []string{"/files/a", "/files/.txt", "/files/a.", "/api/v1/users", "/img/abc-thumb.png", "/img/1001.png"} {
+		if handlers, _ := route.Lookup(path); handlers != nil {
+			t.Fatalf("%s: should not match", path)
+		}
+	}
+}
+
+func TestRouteLookupColon(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+
+	// "\:" 表示字面量 ":"
+	a.Get("/v1/users\\:batchGet", emptyHandle).Name("batch")
+	a.Get("/v1/:name\\:cancel", emptyHandle).Name("cancel")
+	a.Get("/v1/\\:id", emptyHandle)
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		expected map[string]string
+	}{
+		{"/v1/users:batchGet", nil},
+		{"/v1/job:cancel", map[string]string{"name": "job"}},
+		{"/v1/:id", nil},
+	}
+	for _, test := range tests {
+		handlers, dynamic := r.Lookup("GET", test.path)
+		if handlers == nil || len(dynamic) != len(test.expected) {
+			t.Fatalf("%s: invalid result: %v", test.path, dynamic)
+		}
+		for key, value := range test.expected {
+			if dynamic[key] != value {
+				t.Fatalf("%s: invalid dynamic: %v", test.path, dynamic)
+			}
+		}
+	}
+
+	for _, path := range []string{"/v1/usersXYZ", "/v1/users:list", "/v1/job", "/v1/123"} {
+		if handlers, _ := r.Lookup("GET", path); handlers != nil {
+			t.Fatalf("%s: should not match", path)
+		}
+	}
+
+	if u, err := r.URL("batch", nil, nil); err != nil || u != "/v1/users:batchGet" {
+		t.Fatalf("invalid url: %s %v", u, err)
+	}
+	if u, err := r.URL("cancel", map[string]string{"name": "job"}, nil); err != nil || u != "/v1/job:cancel" {
+		t.Fatalf("invalid url: %s %v", u, err)
+	}
+}
+
+func TestRouteBuildMultipleFailed(t *testing.T) {
+	route := zerorouter.NewRoute()
+
+	// 两个动态参数之间缺少字面量
+	route.Insert("/files/:name:ext", emptyHandle)
+	if route.Build(nil) == nil {
+		t.Fatal("adjacent params")
+	}
+
+	// 字面量中不可以包含 |
+	route.Reset()
+	route.Insert("/files/:name.a|b", emptyHandle)
+	if route.Build(nil) == nil {
+		t.Fatal("invalid literal")
+	}
+}
+
+func TestRouteLookupOptional(t *testing.T) {
+	route := zerorouter.NewRoute()
+	route.Insert("/archive/:year/:month?", emptyHandle)
+	route.Insert("/list/:page(^\\d+$)?=1", emptyHandle)
+	route.Insert("/date/:year?=2024/:month?=01", emptyHandle)
+	if err := route.Build(nil); err != nil {
+		t.Fatal("build failed")
+	}
+
+	tests := []struct {
+		path     string
+		expected map[string]string
+	}{
+		{"/archive/2024", map[string]string{"year": "2024", "month": ""}},
+		{"/archive/2024/05", map[string]string{"year": "2024", "month": "05"}},
+		{"/list", map[string]string{"page": "1"}},
+		{"/list/3", map[string]string{"page": "3"}},
+		{"/date", map[string]string{"year": "2024", "month": "01"}},
+		{"/date/2023", map[string]string{"year": "2023", "month": "01"}},
+		{"/date/2023/12", map[string]string{"year": "2023", "month": "12"}},
+	}
+
+	for _, test := range tests {
+		handlers, dynamic := route.Lookup(test.path)
+		if handlers == nil {
+			t.Fatalf("%s: not found", test.path)
+		}
+
+		if len(dynamic) != len(test.expected) {
+			t.Fatalf("%s: invalid dynamic: %v", test.path, dynamic)
+		}
+
+		for key, value := range test.expected {
+			if dynamic[key] != value {
+				t.Fatalf("%s: invalid dynamic: %v", test.path, dynamic)
+			}
+		}
+	}
+
+	for _, path := range []string{"/archive", "/list/abc", "/archive/2024/05/01"} {
+		if handlers, _ := route.Lookup(path); handlers != nil {
+			t.Fatalf("%s: should not match", path)
+		}
+	}
+}
+
+func TestRouteBuildOptionalFailed(t *testing.T) {
+	route := zerorouter.NewRoute()
+
+	// 可选参数之后只能是可选参数
+	route.Insert("/archive/:year?/:month", emptyHandle)
+	if route.Build(nil) == nil {
+		t.Fatal("required param after optional param")
+	}
+
+	route.Reset()
+	route.Insert("/archive/:year?/list", emptyHandle)
+	if route.Build(nil) == nil {
+		t.Fatal("static path after optional param")
+	}
+}
+
+func TestRouteLookupNamedWildcard(t *testing.T) {
+	route := zerorouter.NewRoute()
+	route.Insert("/files/*filepath", emptyHandle)
+	route.Insert("/docs/*page+(\\.md$)", emptyHandle)
+	route.Insert("/docs/*other+", emptyHandle)
+	route.Insert("/static/*", emptyHandle)
+	if err := route.Build(nil); err != nil {
+		t.Fatal("build failed")
+	}
+
+	tests := []struct {
+		path     string
+		key      string
+		expected string
+	}{
+		{"/files/css/a.css", "filepath", "css/a.css"},
+		{"/files/", "filepath", ""},
+		{"/docs/guide/intro.md", "page", "guide/intro.md"},
+		{"/docs/guide/logo.png", "other", "guide/logo.png"},
+		{"/static/js/a.js", "*", "js/a.js"},
+	}
+
+	for _, test := range tests {
+		handlers, dynamic := route.Lookup(test.path)
+		if handlers == nil {
+			t.Fatalf("%s: not found", test.path)
+		}
+
+		if len(dynamic) != 1 || dynamic[test.key] != test.expected {
+			t.Fatalf("%s: invalid dynamic: %v", test.path, dynamic)
+		}
+	}
+
+	// 内容不可以为空
+	if handlers, _ := route.Lookup("/docs/"); handlers != nil {
+		t.Fatal("empty wildcard matched")
+	}
+}
+
+func TestRouteLookupWildcardValidator(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+	r.RegisterRouterValidator("less4", less4)
+
+	route := zerorouter.NewRoute()
+	route.Insert("/short/*code|less4|", emptyHandle)
+	if err := route.Build(r); err != nil {
+		t.Fatal("build failed")
+	}
+
+	if handlers, dynamic := route.Lookup("/short/a/b"); handlers == nil || dynamic["code"] != "a/b" {
+		t.Fatal("lookup failed")
+	}
+
+	if handlers, _ := route.Lookup("/short/abcd"); handlers != nil {
+		t.Fatal("validator not applied")
+	}
+
+	// 名称之后只能是 +，正则表达式，验证函数
+	route.Reset()
+	route.Insert("/short/*code-x", emptyHandle)
+	if route.Build(r) == nil {
+		t.Fatal("invalid wildcard")
+	}
+}
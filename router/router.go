@@ -1,6 +1,17 @@
 package router
 
 import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	_path "path"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
 	zeroapi "github.com/zerogo-hub/zero-api"
 )
 
@@ -11,25 +22,102 @@ type router struct {
 	// prefix 路由前缀
 	prefix string
 
-	// routes 按照 Method 存储路由
-	routes map[string]Route
+	// mode 路由模式，见 zeroapi.RouterModeXxx，只有默认路由表使用，请求处理时读取，使用原子操作
+	mode atomic.Int64
+
+	// table 路由表，包括路由，路由名称，元数据
+	// Build 之后不再修改，运行时注册或者删除路由时复制一份，重新构建后整体替换
+	table atomic.Pointer[routeTable]
+
+	// mu 修改路由表时加锁，只有默认路由表使用
+	mu sync.Mutex
+
+	// built 是否已经调用 Build，只有默认路由表使用
+	built bool
+
+	// batchMu Batch 执行期间加锁，多个 Batch 依次执行，只有默认路由表使用
+	batchMu sync.Mutex
+
+	// batch Batch 正在执行，修改暂存在 staged 中，只有默认路由表使用
+	batch bool
+
+	// pending Build 之后在 Batch 之外的修改暂存在 staged 中，还未生效，见 loadTable，只有默认路由表使用
+	pending atomic.Bool
+
+	// stagedRouters 暂存了修改的路由表，见 staged，只有默认路由表使用
+	stagedRouters []*router
+
+	// staged 暂存的路由表，Batch 执行期间未 Build，见 Batch，其它时候已 Build 但还未生效，见 update
+	staged *routeTable
+
+	// savedRegistered，savedConditions Batch 开始修改前的 registered，conditions，Batch 失败时恢复
+	savedRegistered map[string]bool
+	savedConditions map[string]int
+
+	// validatorsMu 注册和读取验证函数时加锁，验证函数由所有路由表共享，只有默认路由表使用
+	validatorsMu sync.RWMutex
 
 	// validators 存储验证函数
 	validators map[string]zeroapi.RouterValidator
+
+	// validatorFactories 存储带参数的验证函数的生成函数
+	validatorFactories map[string]zeroapi.RouterValidatorFactory
+
+	// urls URL 使用的已解析的路由，路由全路径 -> []urlSegment，只有默认路由表使用
+	urls sync.Map
+
+	// lastPath 最近一次注册的路由全路径，用于 Name
+	lastPath string
+
+	// lastKeys 最近一次注册的路由的元数据 key，用于 Meta，Match 时可能有多个
+	lastKeys []string
+
+	// registered 已注册的路由，Method + " " + 路由全路径
+	registered map[string]bool
+
+	// conditions 已注册的带有匹配条件的路由数量，Method + " " + 路由全路径 -> 数量
+	conditions map[string]int
+
+	// errors 注册路由时的错误，Build 时返回
+	errors []error
+
+	// parent 域名路由表所属的默认路由表，版本路由表所属的默认路由表或者域名路由表，默认路由表为 nil
+	// 域名路由表，版本路由表与默认路由表共享前缀，路由模式，验证函数
+	parent *router
+
+	// host 域名路由表对应的域名，例如 {tenant}.example.com，默认路由表为 ""
+	host string
+
+	// hosts 所有域名路由表，只有默认路由表使用，新增时整体替换
+	hosts atomic.Pointer[[]*router]
+
+	// version 版本路由表对应的 API 版本，例如 "2"，其它路由表为 ""
+	version string
+
+	// versions 所有版本路由表，版本路由表不使用，新增时整体替换
+	versions atomic.Pointer[[]*router]
 }
 
 // NewRouter 创建一个 zeroapi.Router 实例
 func NewRouter(app zeroapi.App) zeroapi.Router {
-	return &router{
+	r := &router{
 		app:        app,
-		routes:     make(map[string]Route, len(zeroapi.AllMethods())),
 		validators: make(map[string]zeroapi.RouterValidator),
+		registered: make(map[string]bool),
+		conditions: make(map[string]int),
+
+		validatorFactories: make(map[string]zeroapi.RouterValidatorFactory),
 	}
+	r.table.Store(newRouteTable())
+
+	return r
 }
 
 // Prefix 设置前缀，设置前就已添加的路由不会有该前缀
 // 例如: prefix = "/blog"，则 "/user" -> "/blog/user"
 func (r *router) Prefix(prefix string) {
+	r = r.root()
+
 	if len(prefix) == 0 {
 		return
 	}
@@ -38,73 +126,582 @@ func (r *router) Prefix(prefix string) {
 		prefix = "/" + prefix
 	}
 
-	r.prefix = prefix
+	// 去除末尾的 "/"，避免拼接后出现 "//"
+	r.prefix = strings.TrimRight(prefix, "/")
+}
+
+// PrefixPath 获取路由前缀，域名路由表返回默认路由表的前缀
+func (r *router) PrefixPath() string {
+	return r.root().prefix
+}
+
+// SetMode 设置路由模式，见 zeroapi.RouterModeXxx，多个模式使用 | 组合
+func (r *router) SetMode(mode int) {
+	r.root().mode.Store(int64(mode))
+}
+
+// Mode 获取路由模式
+func (r *router) Mode() int {
+	return int(r.root().mode.Load())
 }
 
 // Register 注册路由处理函数，以及中间件
-// method: HTTP Method，见 core/const.go Methodxxxx
+// method: HTTP Method，见 core/const.go Methodxxxx，也可以是自定义 Method，zeroapi.MethodAny 表示同时注册多个 Method
 // path: 路径，以 "/" 开头，不可以为空
 // handles: 处理函数和路由级别中间件，匹配成功后会调用该函数
-func (r *router) Register(method, path string, handlers ...zeroapi.Handler) bool {
+// 失败时返回 *zeroapi.RouteError，该路由不会被添加，Build 之前的错误会被记录并在 Build 时返回
+// Build 之后也可以调用，复制路由表添加路由并重新构建，成功后整体替换，不影响正在处理的请求
+func (r *router) Register(method, path string, handlers ...zeroapi.Handler) error {
+	return r.Match([]string{method}, path, handlers...)
+}
+
+// Match 为多个 Method 注册同一个路由，Name 和 Meta 作用于所有注册成功的 Method
+// 某个 Method 注册失败时不影响其它 Method，返回所有的错误
+func (r *router) Match(methods []string, path string, handlers ...zeroapi.Handler) error {
+	return r.match(methods, path, nil, 0, handlers...)
+}
+
+// match 为多个 Method 注册路由，predicates 不为空时为带有匹配条件的路由
+// timeout > 0 时同时设置超时时间，与路由一起生效，用于组路由级别超时时间
+func (r *router) match(methods []string, path string, predicates []zeroapi.RoutePredicate, timeout time.Duration, handlers ...zeroapi.Handler) error {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	r.lastPath = ""
+	r.lastKeys = nil
+
+	methods = expandMethods(methods)
+	if len(methods) == 0 {
+		return r.registerError(&zeroapi.RouteError{Host: r.host, Path: path, Err: fmt.Errorf("%w: no methods", zeroapi.ErrInvalidMethod)})
+	}
+
+	var errs []error
+	for _, method := range methods {
+		if err := r.register(method, path, predicates, timeout, handlers...); err != nil {
+			errs = append(errs, r.registerError(err))
+		}
+	}
+
+	if r.lastPath != "" {
+		r.warnUnanchored(methods, r.lastPath)
+	}
+
+	if len(errs) > 0 {
+		return joinErrors(errs)
+	}
+
+	return nil
+}
+
+// register 注册一个 Method 的路由，调用前需要持有默认路由表的 mu
+// 带有匹配条件的路由不检查是否重复，同一个路由可以注册多次
+func (r *router) register(method, path string, predicates []zeroapi.RoutePredicate, timeout time.Duration, handlers ...zeroapi.Handler) error {
 	if len(path) == 0 {
-		return false
-	} else if len(handlers) == 0 {
-		return false
+		return &zeroapi.RouteError{Method: method, Host: r.host, Err: fmt.Errorf("%w: empty path", zeroapi.ErrInvalidPath)}
 	}
 
 	if path[0] != '/' {
 		path = "/" + path
 	}
 
-	if r.prefix != "" {
-		path = r.prefix + "/" + path
+	if prefix := r.root().prefix; prefix != "" {
+		path = prefix + path
 	}
 
-	re := r.routes[method]
-	if re == nil {
-		re = NewRoute()
-		r.routes[method] = re
+	if !isValidMethod(method) {
+		return &zeroapi.RouteError{Method: method, Host: r.host, Path: path, Err: fmt.Errorf("%w: \"%s\"", zeroapi.ErrInvalidMethod, method)}
 	}
 
-	re.Insert(path, handlers...)
+	if len(handlers) == 0 {
+		return &zeroapi.RouteError{Method: method, Host: r.host, Path: path, Err: fmt.Errorf("%w: no handlers", zeroapi.ErrInvalidPath)}
+	}
 
-	return true
+	key := metaKey(method, path)
+	conditional := len(predicates) > 0
+	if conditional {
+		key = variantKey(method, path, r.conditions[key])
+	}
+
+	err := r.update("", nil, func(t *routeTable) error {
+		if err := r.checkPath(t, method, path, conditional); err != nil {
+			return err
+		}
+
+		re := t.routes[method]
+		if re == nil {
+			re = NewRoute()
+			t.routes[method] = re
+		}
+
+		if conditional {
+			re.InsertWhen(path, predicates, handlers...)
+		} else {
+			re.Insert(path, handlers...)
+		}
+
+		if timeout > 0 {
+			t.timeouts[key] = timeout
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if conditional {
+		r.conditions[metaKey(method, path)]++
+	} else {
+		r.registered[key] = true
+	}
+
+	r.lastPath = path
+	r.lastKeys = append(r.lastKeys, key)
+
+	return nil
+}
+
+// registerError Build 之前的错误需要记录，Build 之后只返回
+func (r *router) registerError(err error) error {
+	if !r.root().built {
+		r.errors = append(r.errors, err)
+	}
+
+	return err
+}
+
+// Name 为最近一次注册的路由命名，名称不可重复，重复时记录错误，Build 时返回
+func (r *router) Name(name string) bool {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if name == "" || r.lastPath == "" {
+		return false
+	}
+
+	if fullPath, exist := r.currentTable().names[name]; exist {
+		// 与注册路由时的错误相同，Build 时返回
+		_ = r.registerError(&zeroapi.RouteError{Host: r.host, Path: r.lastPath, Err: fmt.Errorf("%w: \"%s\" is used by \"%s\"", zeroapi.ErrDuplicateName, name, fullPath)})
+		return false
+	}
+
+	err := r.updateAttrs(func(t *routeTable) {
+		t.names[name] = r.lastPath
+	})
+
+	return err == nil
 }
 
 // Build 解析路由，包括动态参数，正则表达式，验证函数的解析，路由路径查找优化
-func (r *router) Build() bool {
-	for _, re := range r.routes {
-		if !re.Build(r) {
-			return false
-		}
+// 同时解析所有的域名路由表，只需要调用一次，成功之后注册或者删除路由会立即生效
+// 失败时返回所有的错误，包括注册路由时的错误，每一个都是 *zeroapi.RouteError
+func (r *router) Build() error {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if root.built {
+		return nil
 	}
 
-	return true
+	errs := root.buildAll()
+	for _, h := range root.hostList() {
+		errs = append(errs, h.buildAll()...)
+	}
+
+	if len(errs) > 0 {
+		return joinErrors(errs)
+	}
+
+	root.built = true
+
+	return nil
+}
+
+// buildAll 解析路由表以及所有版本路由表
+func (r *router) buildAll() []error {
+	errs := r.build()
+	for _, v := range r.versionList() {
+		errs = append(errs, v.build()...)
+	}
+
+	return errs
+}
+
+func (r *router) build() []error {
+	errs := append([]error(nil), r.errors...)
+	errs = append(errs, r.checkValidators()...)
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return r.buildTable(r.table.Load())
 }
 
 // Lookup 查找路由
 func (r *router) Lookup(method, path string) ([]zeroapi.Handler, map[string]string) {
-	if re := r.routes[method]; re != nil {
+	if re := r.loadTable().routes[method]; re != nil {
 		return re.Lookup(path)
 	}
 
 	return nil, nil
 }
 
+// Allowed 获取能够匹配 path 的所有 Method
+// 按照 zeroapi.AllMethods() 的顺序排列，自定义 Method 按字母序排在最后
+// 开启 zeroapi.RouterModeAutoHead，zeroapi.RouterModeAutoOptions 时，也会包含自动处理的 HEAD 和 OPTIONS
+func (r *router) Allowed(path string) []string {
+	routes := r.loadTable().routes
+	matched := make(map[string]bool, len(routes))
+
+	for method, re := range routes {
+		if r.hasNode(re, path) {
+			matched[method] = true
+		}
+	}
+
+	if len(matched) == 0 {
+		return nil
+	}
+
+	mode := r.Mode()
+
+	if mode&zeroapi.RouterModeAutoHead != 0 && matched[zeroapi.MethodGet] {
+		matched[zeroapi.MethodHead] = true
+	}
+
+	if mode&zeroapi.RouterModeAutoOptions != 0 {
+		matched[zeroapi.MethodOptions] = true
+	}
+
+	return sortMethods(matched)
+}
+
+// sortMethods 按照 zeroapi.AllMethods() 的顺序排列，自定义 Method 按字母序排在最后
+func sortMethods(methods map[string]bool) []string {
+	sorted := make([]string, 0, len(methods))
+
+	for _, method := range zeroapi.AllMethods() {
+		if methods[method] {
+			sorted = append(sorted, method)
+		}
+	}
+
+	var customs []string
+	for method := range methods {
+		if !isKnownMethod(method) {
+			customs = append(customs, method)
+		}
+	}
+	sort.Strings(customs)
+
+	return append(sorted, customs...)
+}
+
+// FixPath 根据路由模式修正 path，返回能够匹配的规范路径，无法修正时返回 ""
+// 见 zeroapi.RouterModeRedirectTrailingSlash，zeroapi.RouterModeRedirectFixedPath
+func (r *router) FixPath(method, path string) string {
+	re := r.loadTable().routes[method]
+	if re == nil {
+		return ""
+	}
+
+	mode := r.Mode()
+	isTrailingSlash := mode&zeroapi.RouterModeRedirectTrailingSlash != 0
+
+	if mode&zeroapi.RouterModeRedirectFixedPath != 0 {
+		if fixed := cleanPath(path); fixed != path {
+			if r.hasNode(re, fixed) {
+				return fixed
+			}
+
+			if isTrailingSlash {
+				if fixed = toggleTrailingSlash(fixed); fixed != "" {
+					if r.hasNode(re, fixed) {
+						return fixed
+					}
+				}
+			}
+		}
+	}
+
+	if isTrailingSlash {
+		if fixed := toggleTrailingSlash(path); fixed != "" {
+			if r.hasNode(re, fixed) {
+				return fixed
+			}
+		}
+	}
+
+	return ""
+}
+
+// hasNode path 能否匹配 re 中含有路由处理函数的节点，包括只有带匹配条件路由的节点
+// 与 LookupRouteParams 相同，开启 zeroapi.RouterModeUseRawPath 时动态参数的值先解码，再检查正则表达式和验证函数
+func (r *router) hasNode(re Route, path string) bool {
+	var params zeroapi.Params
+	return re.(*route).lookup(path, &params, r.lookupOption(nil, false)) != nil
+}
+
+// lookupOption 查找路由的选项，checked 为 true 时使用 req 检查匹配条件，见 lookupOption
+func (r *router) lookupOption(req *http.Request, checked bool) lookupOption {
+	return lookupOption{checked: checked, req: req, unescape: r.Mode()&zeroapi.RouterModeUseRawPath != 0}
+}
+
+// cleanPath 清理路径中多余的 "/"，"."，".."，保留末尾的 "/"
+// 例如: //a/./b/../c/ -> /a/c/
+func cleanPath(path string) string {
+	if path == "" {
+		return "/"
+	}
+
+	fixed := _path.Clean("/" + path)
+	if fixed != "/" && path[len(path)-1] == '/' {
+		fixed += "/"
+	}
+
+	return fixed
+}
+
+// toggleTrailingSlash 添加或者去除末尾的 "/"，根路径返回 ""
+func toggleTrailingSlash(path string) string {
+	if path == "" || path == "/" {
+		return ""
+	}
+
+	if path[len(path)-1] == '/' {
+		return path[:len(path)-1]
+	}
+
+	return path + "/"
+}
+
+// unescapeValue 解码动态参数的值，解码失败时保持原样
+func unescapeValue(value string) string {
+	if strings.IndexByte(value, '%') == -1 {
+		return value
+	}
+
+	if unescaped, err := url.PathUnescape(value); err == nil {
+		return unescaped
+	}
+
+	return value
+}
+
+// anyMethods zeroapi.MethodAny 对应的 Method
+var anyMethods = []string{
+	zeroapi.MethodGet,
+	zeroapi.MethodPost,
+	zeroapi.MethodPut,
+	zeroapi.MethodDelete,
+	zeroapi.MethodHead,
+	zeroapi.MethodPatch,
+	zeroapi.MethodOptions,
+}
+
+// expandMethods 展开 zeroapi.MethodAny，并去除重复的 Method
+func expandMethods(methods []string) []string {
+	out := make([]string, 0, len(methods))
+	exist := make(map[string]bool, len(methods))
+
+	for _, method := range methods {
+		expanded := []string{method}
+		if method == zeroapi.MethodAny {
+			expanded = anyMethods
+		}
+
+		for _, m := range expanded {
+			if !exist[m] {
+				exist[m] = true
+				out = append(out, m)
+			}
+		}
+	}
+
+	return out
+}
+
+// isValidMethod Method 只能由 HTTP token 字符组成，例如 GET，PROPFIND
+func isValidMethod(method string) bool {
+	if method == "" {
+		return false
+	}
+
+	for i := 0; i < len(method); i++ {
+		c := method[i]
+		if isNameChar(c) || strings.IndexByte("!#$%&'*+-.^`|~", c) != -1 {
+			continue
+		}
+
+		return false
+	}
+
+	return true
+}
+
+func isKnownMethod(method string) bool {
+	for _, m := range zeroapi.AllMethods() {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
 // RegisterRouterValidator 注册路由验证函数
+// 同名的验证函数只能注册一次，但是可以覆盖框架自带的验证函数
 func (r *router) RegisterRouterValidator(name string, validator zeroapi.RouterValidator) {
+	root := r.root()
+	root.validatorsMu.Lock()
+	defer root.validatorsMu.Unlock()
+
 	if _, exist := r.validators[name]; exist {
 		return
 	}
 
 	r.validators[name] = validator
+
+	// 可能覆盖了框架自带的验证函数
+	root.clearURLs()
 }
 
-// Validator 获取路由验证函数
+// clearURLs 清空 URL 使用的已解析的路由
+func (r *router) clearURLs() {
+	r.urls.Range(func(key, _ interface{}) bool {
+		r.urls.Delete(key)
+		return true
+	})
+}
+
+// Validator 获取路由验证函数，包括框架自带的验证函数
 func (r *router) Validator(name string) zeroapi.RouterValidator {
+	root := r.root()
+	root.validatorsMu.RLock()
+	defer root.validatorsMu.RUnlock()
+
 	if f, exist := r.validators[name]; exist {
 		return f
 	}
 
+	if f, exist := defaultValidators[name]; exist {
+		return f
+	}
+
+	return nil
+}
+
+// Validators 获取所有可用的验证函数名称，包括框架自带的验证函数，按字母序排列
+// 带参数的验证函数名称以 "()" 结尾，例如 "len()"
+func (r *router) Validators() []string {
+	root := r.root()
+	root.validatorsMu.RLock()
+	defer root.validatorsMu.RUnlock()
+
+	exist := make(map[string]bool)
+
+	for name := range defaultValidators {
+		exist[name] = true
+	}
+	for name := range r.validators {
+		exist[name] = true
+	}
+	for name := range defaultValidatorFactories {
+		exist[name+"()"] = true
+	}
+	for name := range r.validatorFactories {
+		exist[name+"()"] = true
+	}
+
+	names := make([]string, 0, len(exist))
+	for name := range exist {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	return names
+}
+
+// RegisterRouterValidatorFactory 注册带参数的验证函数的生成函数
+// 同名的生成函数只能注册一次，但是可以覆盖框架自带的生成函数
+func (r *router) RegisterRouterValidatorFactory(name string, factory zeroapi.RouterValidatorFactory) {
+	root := r.root()
+	root.validatorsMu.Lock()
+	defer root.validatorsMu.Unlock()
+
+	if _, exist := r.validatorFactories[name]; exist {
+		return
+	}
+
+	r.validatorFactories[name] = factory
+
+	// 可能覆盖了框架自带的生成函数
+	root.clearURLs()
+}
+
+// ValidatorFactory 获取带参数的验证函数的生成函数，包括框架自带的生成函数
+func (r *router) ValidatorFactory(name string) zeroapi.RouterValidatorFactory {
+	root := r.root()
+	root.validatorsMu.RLock()
+	defer root.validatorsMu.RUnlock()
+
+	if f, exist := r.validatorFactories[name]; exist {
+		return f
+	}
+
+	if f, exist := defaultValidatorFactories[name]; exist {
+		return f
+	}
+
+	return nil
+}
+
+// checkValidators 检查路由中使用的验证函数是否都已注册，参数是否正确
+// 每个路由最多返回一个错误，验证函数未注册时，错误中列出所有可用的验证函数
+func (r *router) checkValidators() []error {
+	var errs []error
+
+	for _, info := range r.Routes() {
+		if info.Host != r.host || info.Version != r.version {
+			continue
+		}
+
+		if err := r.checkRouteValidators(info); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errs
+}
+
+func (r *router) checkRouteValidators(info zeroapi.RouteInfo) error {
+	for _, param := range info.Params {
+		for _, name := range param.Validators {
+			_, err := resolveValidator(r, name)
+			if err == nil {
+				continue
+			}
+
+			if errors.Is(err, zeroapi.ErrValidatorNotFound) {
+				err = fmt.Errorf("%w, available: %s", err, strings.Join(r.Validators(), ","))
+			}
+
+			e := &zeroapi.RouteError{Method: info.Method, Host: info.Host, Path: info.Path, Err: err}
+			for _, segment := range buildPath(info.Path) {
+				if strings.Contains(segment, "|"+name+"|") {
+					e.Segment = segment
+					break
+				}
+			}
+
+			return e
+		}
+	}
+
 	return nil
 }
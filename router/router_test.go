@@ -1,6 +1,8 @@
 package router_test
 
 import (
+	"errors"
+	"strings"
 	"testing"
 
 	zeroapi "github.com/zerogo-hub/zero-api"
@@ -18,18 +20,60 @@ func TestRouterRegister(t *testing.T) {
 	r.Prefix("blog")
 
 	// 注册错误的路由
-	if r.Register(zeroapi.MethodGet, "") {
+	if r.Register(zeroapi.MethodGet, "") == nil {
 		t.Fatal("invalid path")
 	}
 
 	// 注册错误的路由
-	if r.Register(zeroapi.MethodGet, "/list") {
+	if r.Register(zeroapi.MethodGet, "/list") == nil {
 		t.Fatal("miss handlers")
 	}
 
 	// 注册正确的路由
-	if !r.Register(zeroapi.MethodGet, "/list", emptyHandle) {
-		t.Fatal("failed")
+	if err := r.Register(zeroapi.MethodGet, "/list", emptyHandle); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouterMatch(t *testing.T) {
+	r := zeroapp.NewApp().Router()
+
+	if err := r.Register(zeroapi.MethodAny, "/any", emptyHandle); err != nil {
+		t.Fatal(err)
+	}
+
+	// GET 已经由 MethodAny 注册，其它 Method 不受影响
+	err := r.Match([]string{zeroapi.MethodGet, zeroapi.MethodTrace, zeroapi.MethodTrace}, "/any", emptyHandle)
+	if !errors.Is(err, zeroapi.ErrDuplicateRoute) {
+		t.Fatalf("invalid error: %v", err)
+	}
+	if handlers, _ := r.Lookup(zeroapi.MethodTrace, "/any"); handlers == nil {
+		t.Fatal("trace failed")
+	}
+
+	if err := r.Register("BAD METHOD", "/any", emptyHandle); !errors.Is(err, zeroapi.ErrInvalidMethod) {
+		t.Fatalf("invalid error: %v", err)
+	}
+	if err := r.Match(nil, "/any", emptyHandle); !errors.Is(err, zeroapi.ErrInvalidMethod) {
+		t.Fatalf("invalid error: %v", err)
+	}
+
+	if err := r.Remove(zeroapi.MethodGet, "/any"); err != nil {
+		t.Fatal(err)
+	}
+	if allowed := r.Allowed("/any"); len(allowed) == 0 || allowed[0] == zeroapi.MethodGet || allowed[len(allowed)-1] != zeroapi.MethodTrace {
+		t.Fatalf("invalid allowed: %v", allowed)
+	}
+
+	// MethodAny 删除该路径下的所有 Method，包括不属于 Any 的 TRACE
+	if err := r.Remove(zeroapi.MethodAny, "/any"); err != nil {
+		t.Fatal(err)
+	}
+	if allowed := r.Allowed("/any"); allowed != nil {
+		t.Fatalf("invalid allowed: %v", allowed)
+	}
+	if err := r.Remove(zeroapi.MethodAny, "/any"); !errors.Is(err, zeroapi.ErrRouteNotFound) {
+		t.Fatalf("invalid error: %v", err)
 	}
 }
 
@@ -39,7 +83,7 @@ func TestRouterBuildFailed(t *testing.T) {
 
 	r.Register(zeroapi.MethodGet, "/list/:id(\\d+", emptyHandle)
 
-	if r.Build() {
+	if r.Build() == nil {
 		t.Fatal("invalid regexp")
 	}
 }
@@ -54,8 +98,8 @@ func TestRouterBuildSuccess(t *testing.T) {
 
 	r.Register(zeroapi.MethodGet, "/list/:id(\\d+)|isNum|", emptyHandle)
 
-	if !r.Build() {
-		t.Fatal("failed")
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
 	}
 }
 
@@ -66,8 +110,8 @@ func TestRouterLookup(t *testing.T) {
 
 	r.Register(zeroapi.MethodGet, "/list/:id(\\d+)|less4|", emptyHandle)
 
-	if !r.Build() {
-		t.Fatal("build failed")
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
 	}
 
 	// 正确的找到
@@ -101,8 +145,8 @@ func TestRouterLookup2(t *testing.T) {
 	g.Post("/v1/signout", emptyHandle)
 	g.Put("/v1/password", emptyHandle)
 
-	if !r.Build() {
-		t.Fatal("build failed")
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
 	}
 
 	if handlers, _ := r.Lookup(zeroapi.MethodPost, "/account/v1/signin"); handlers == nil {
@@ -121,8 +165,8 @@ func TestRouterLookup3(t *testing.T) {
 	a.Get("/app", emptyHandle)
 	a.Get("/app/category", emptyHandle)
 	a.Get("/app/category/v1", emptyHandle)
-	if !r.Build() {
-		t.Fatal("build failed")
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
 	}
 
 	// 查找不存在的路由
@@ -133,3 +177,74 @@ func TestRouterLookup3(t *testing.T) {
 		t.Fatal("lookup /app/recharge/v1 failed")
 	}
 }
+
+func TestRouterAllowed(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+
+	a.Get("/user/:id", emptyHandle)
+	a.Delete("/user/:id", emptyHandle)
+	a.Post("/user", emptyHandle)
+	r.Register("PROPFIND", "/user/:id", emptyHandle)
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	allowed := r.Allowed("/user/1001")
+	if strings.Join(allowed, ",") != "GET,DELETE,PROPFIND" {
+		t.Fatalf("invalid allowed: %v", allowed)
+	}
+
+	if allowed := r.Allowed("/account"); len(allowed) != 0 {
+		t.Fatalf("invalid allowed: %v", allowed)
+	}
+}
+
+func TestRouterFixPath(t *testing.T) {
+	a := zeroapp.NewApp(zeroapp.WithRedirectTrailingSlash(true), zeroapp.WithRedirectFixedPath(true))
+	r := a.Router()
+
+	a.Get("/user", emptyHandle)
+	a.Get("/blog/:id", emptyHandle)
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"/user/":              "/user",
+		"//user":              "/user",
+		"/a/../user":          "/user",
+		"/./user/":            "/user",
+		"/blog//1001":         "/blog/1001",
+		"/user/name":          "",
+		"/account":            "",
+		"/blog/../account/1/": "",
+	}
+
+	for path, expected := range tests {
+		if fixed := r.FixPath(zeroapi.MethodGet, path); fixed != expected {
+			t.Fatalf("fix %s, expected: %s, got: %s", path, expected, fixed)
+		}
+	}
+
+	if fixed := r.FixPath(zeroapi.MethodPost, "/user/"); fixed != "" {
+		t.Fatalf("invalid method: %s", fixed)
+	}
+}
+
+func TestRouterFixPathDisabled(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+
+	a.Get("/user", emptyHandle)
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if fixed := r.FixPath(zeroapi.MethodGet, "/user/"); fixed != "" {
+		t.Fatalf("invalid fixed: %s", fixed)
+	}
+}
@@ -0,0 +1,240 @@
+package router
+
+import (
+	"fmt"
+	"strings"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// segmentPart 一个路径片段中的一部分，字面量或者动态参数
+//
+// 示例: /v:major.:minor
+// 拆分为: "v", ":major", ".", ":minor"
+type segmentPart struct {
+	// literal 字面量
+	literal string
+
+	// node 动态参数，为 nil 时表示字面量
+	node *routeNode
+}
+
+// splitSegment 将一个路径片段(不包含开头的 /)拆分为字面量和动态参数
+// 动态参数格式为 :name(regexp)|validator...|，name 由字母，数字，下划线组成
+// 字面量中不可以包含 ( ) |，字面量中的 ":" 需要写为 "\:"，返回的字面量保持转义，见 unescapeColon
+//
+// 示例: :id(^\d+$)|less4|-thumb.png
+// 结果: ":id(^\d+$)|less4|", "-thumb.png"
+func splitSegment(segment string) ([]string, error) {
+	var tokens []string
+
+	i := 0
+	for i < len(segment) {
+		if segment[i] != DynamicCharacter {
+			// 字面量
+			j := indexColon(segment[i:])
+			if j == -1 {
+				j = len(segment)
+			} else {
+				j += i
+			}
+
+			literal := segment[i:j]
+			if strings.ContainsAny(literal, ")|") {
+				return nil, fmt.Errorf("%w: unexpected \"%s\"", zeroapi.ErrUnbalanced, literal)
+			}
+			if strings.Contains(literal, "(") {
+				return nil, fmt.Errorf("%w: literal \"%s\" contains \"(\"", zeroapi.ErrInvalidSegment, literal)
+			}
+
+			tokens = append(tokens, literal)
+			i = j
+			continue
+		}
+
+		j := i + 1
+		for j < len(segment) && isNameChar(segment[j]) {
+			j++
+		}
+		if j == i+1 {
+			return nil, fmt.Errorf("%w: missing param name", zeroapi.ErrInvalidSegment)
+		}
+
+		// 正则表达式
+		if j < len(segment) && segment[j] == '(' {
+			end := closeParen(segment, j)
+			if end == -1 {
+				return nil, fmt.Errorf("%w: missing \")\"", zeroapi.ErrUnbalanced)
+			}
+			j = end + 1
+		}
+
+		// 验证函数
+		if j < len(segment) && segment[j] == '|' {
+			end := closeValidators(segment, j)
+			if end == -1 {
+				return nil, fmt.Errorf("%w: missing \"|\"", zeroapi.ErrUnbalanced)
+			}
+			j = end + 1
+		}
+
+		tokens = append(tokens, segment[i:j])
+		i = j
+	}
+
+	return tokens, nil
+}
+
+// indexColon 查找第一个未转义的 ":"，未找到返回 -1
+func indexColon(s string) int {
+	for i := 0; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case DynamicCharacter:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// isDynamicSegment 路径片段(包含开头的 /)中是否含有未转义的 ":"，见 splitSegment
+func isDynamicSegment(path string) bool {
+	return len(path) > 1 && indexColon(path[1:]) != -1
+}
+
+// unescapeColon 将字面量中的 "\:" 还原为 ":"
+func unescapeColon(literal string) string {
+	if strings.IndexByte(literal, '\\') == -1 {
+		return literal
+	}
+
+	return strings.ReplaceAll(literal, "\\:", ":")
+}
+
+// isNameChar 动态参数名称可以使用的字符
+func isNameChar(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
+
+// closeParen 查找与 s[start] = '(' 匹配的 ')' 的位置，支持嵌套与转义，未找到返回 -1
+func closeParen(s string, start int) int {
+	depth := 0
+
+	for i := start; i < len(s); i++ {
+		switch s[i] {
+		case '\\':
+			i++
+		case '(':
+			depth++
+		case ')':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// closeValidators 查找验证函数列表 s[start] = '|' 的结束位置，未找到返回 -1
+// 示例: |isNum|less4|，返回最后一个 '|' 的位置
+func closeValidators(s string, start int) int {
+	end := -1
+
+	i := start
+	for i < len(s) && s[i] == '|' {
+		j := i + 1
+		for j < len(s) && isNameChar(s[j]) {
+			j++
+		}
+
+		// 验证函数参数，例如 len(3,16)
+		if j > i+1 && j < len(s) && s[j] == '(' {
+			k := closeParen(s, j)
+			if k == -1 {
+				return -1
+			}
+			j = k + 1
+		}
+
+		if j == i+1 || j >= len(s) || s[j] != '|' {
+			break
+		}
+
+		end = j
+		i = j
+	}
+
+	return end
+}
+
+// parseMultiple 解析含有多个部分的路径片段，例如 /:name.:ext
+func (rn *routeNode) parseMultiple(tokens []string, router zeroapi.Router) error {
+	rn.parts = make([]segmentPart, 0, len(tokens))
+
+	for _, token := range tokens {
+		if token[0] != DynamicCharacter {
+			rn.parts = append(rn.parts, segmentPart{literal: unescapeColon(token)})
+			continue
+		}
+
+		// 两个动态参数之间必须有字面量分隔，否则无法确定边界
+		if n := len(rn.parts); n > 0 && rn.parts[n-1].node != nil {
+			return fmt.Errorf("%w: params must be separated by a literal", zeroapi.ErrInvalidSegment)
+		}
+
+		node := &routeNode{path: "/" + token, flag: DYNAMIC}
+		if err := node.parseParam(router); err != nil {
+			return err
+		}
+
+		rn.parts = append(rn.parts, segmentPart{node: node})
+	}
+
+	rn.flag |= MULTIPLE
+
+	return nil
+}
+
+// matchParts 使用 parts 匹配路径片段，匹配成功时将各个动态参数的值添加到 params 末尾
+// 动态参数优先匹配更长的值，例如 :name.:ext 匹配 a.tar.gz，name = a.tar，ext = gz
+func matchParts(parts []segmentPart, value string, params *zeroapi.Params) bool {
+	if len(parts) == 0 {
+		return value == ""
+	}
+
+	part := parts[0]
+
+	if part.node == nil {
+		if !strings.HasPrefix(value, part.literal) {
+			return false
+		}
+
+		return matchParts(parts[1:], value[len(part.literal):], params)
+	}
+
+	// 最后一个部分，匹配剩余的全部内容
+	if len(parts) == 1 {
+		return value != "" && part.node.appendValue(value, params)
+	}
+
+	// 下一个部分必然是字面量，从后向前查找
+	n := len(*params)
+
+	literal := parts[1].literal
+	for end := strings.LastIndex(value, literal); end > 0; end = strings.LastIndex(value[:end], literal) {
+		if !part.node.appendValue(value[:end], params) {
+			continue
+		}
+
+		if matchParts(parts[1:], value[end:], params) {
+			return true
+		}
+		*params = (*params)[:n]
+	}
+
+	return false
+}
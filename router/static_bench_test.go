@@ -0,0 +1,98 @@
+package router_test
+
+import (
+	"fmt"
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zerorouter "github.com/zerogo-hub/zero-api/router"
+)
+
+// newStaticRoute n 个静态路由，以及少量动态路由
+func newStaticRoute(b *testing.B, n int) (zerorouter.Route, []string) {
+	route := zerorouter.NewRoute()
+
+	paths := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		path := fmt.Sprintf("/api/v%d/resource%d/items", i%10, i)
+		route.Insert(path, emptyHandle)
+		paths = append(paths, path)
+	}
+
+	route.Insert("/api/:version/:resource/items/:id", emptyHandle)
+	route.Insert("/static/*filepath", emptyHandle)
+
+	if err := route.Build(nil); err != nil {
+		b.Fatal(err)
+	}
+
+	return route, paths
+}
+
+func BenchmarkStaticLookup(b *testing.B) {
+	for _, n := range []int{10, 1000, 10000} {
+		route, paths := newStaticRoute(b, n)
+		path := paths[len(paths)-1]
+
+		lookups := []struct {
+			name   string
+			lookup func(path string, params *zeroapi.Params) zeroapi.RouteNode
+		}{
+			{"map", route.LookupParams},
+			{"tree", route.Root().LookupParams},
+		}
+
+		for _, l := range lookups {
+			b.Run(fmt.Sprintf("%s-%d", l.name, n), func(b *testing.B) {
+				var params zeroapi.Params
+				if node := l.lookup(path, &params); node == nil || node.FullPath() != path {
+					b.Fatalf("lookup %s failed", path)
+				}
+
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for i := 0; i < b.N; i++ {
+					params = params[:0]
+					l.lookup(path, &params)
+				}
+			})
+		}
+	}
+}
+
+func TestRouteStatics(t *testing.T) {
+	route := zerorouter.NewRoute()
+	route.Insert("/user/me", emptyHandle)
+	route.Insert("/user/:id", emptyHandle, emptyHandle)
+	route.Insert("/list/:page?=1", emptyHandle)
+	route.Insert("/files/*", emptyHandle)
+	if err := route.Build(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		path     string
+		fullPath string
+		params   int
+	}{
+		{"/user/me", "/user/me", 0},
+		{"/user/1001", "/user/:id", 1},
+		{"/user/:id", "/user/:id", 1},
+		{"/list", "/list/:page?=1", 1},
+		{"/files/*", "/files/*", 1},
+	}
+	for _, test := range tests {
+		var params zeroapi.Params
+		node := route.LookupParams(test.path, &params)
+		if node == nil || node.FullPath() != test.fullPath || len(params) != test.params {
+			t.Fatalf("lookup %s failed: %v", test.path, params)
+		}
+	}
+
+	// Build 之前使用基数树查找
+	route.Insert("/user/you", emptyHandle)
+	if node, _ := route.LookupNode("/user/me"); node == nil || node.FullPath() != "/user/me" {
+		t.Fatal("lookup before build failed")
+	}
+}
@@ -0,0 +1,425 @@
+package router
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// routeTable 路由表，Build 之后只读，可以被多个请求同时使用
+type routeTable struct {
+	// routes 按照 Method 存储路由
+	routes map[string]Route
+
+	// names 路由名称 -> 路由全路径
+	names map[string]string
+
+	// metas 路由元数据，Method + " " + 路由全路径 -> 元数据，带有匹配条件的路由见 variantKey
+	metas map[string]map[string]interface{}
+
+	// timeouts 路由超时时间，key 与 metas 相同
+	timeouts map[string]time.Duration
+}
+
+func newRouteTable() *routeTable {
+	return &routeTable{
+		routes:   make(map[string]Route, len(zeroapi.AllMethods())),
+		names:    make(map[string]string),
+		metas:    make(map[string]map[string]interface{}),
+		timeouts: make(map[string]time.Duration),
+	}
+}
+
+// clone 复制路由表，重新插入所有路由，得到未 Build 的基数树
+// removePath 不为空时，不复制 removeMethods 中各个 Method 的该路由
+func (t *routeTable) clone(removePath string, removeMethods []string) *routeTable {
+	nt := newRouteTable()
+
+	for method, re := range t.routes {
+		walk(re.Root(), func(node zeroapi.RouteNode) {
+			rn, ok := node.(*routeNode)
+			if !ok || !rn.IsHandler() {
+				return
+			}
+
+			if rn.fullPath == removePath && containsString(removeMethods, method) {
+				return
+			}
+
+			nre := nt.routes[method]
+			if nre == nil {
+				nre = NewRoute()
+				nt.routes[method] = nre
+			}
+
+			if len(rn.handlers) > 0 {
+				nre.Insert(rn.fullPath, rn.handlers...)
+			}
+
+			for _, v := range rn.variants {
+				nre.InsertWhen(rn.fullPath, v.predicates, v.handlers...)
+			}
+		})
+	}
+
+	for name, path := range t.names {
+		nt.names[name] = path
+	}
+
+	for key, meta := range t.metas {
+		m := make(map[string]interface{}, len(meta))
+		for k, v := range meta {
+			m[k] = v
+		}
+		nt.metas[key] = m
+	}
+
+	for key, timeout := range t.timeouts {
+		nt.timeouts[key] = timeout
+	}
+
+	return nt
+}
+
+// methods 路由表中的所有 Method
+func (t *routeTable) methods() map[string]bool {
+	methods := make(map[string]bool, len(t.routes))
+	for method := range t.routes {
+		methods[method] = true
+	}
+
+	return methods
+}
+
+// update 修改路由表，调用前需要持有默认路由表的 mu
+// Build 之前直接修改，Build 之后复制一份修改并重新构建，失败时保持不变
+// Build 之后的修改暂存在 staged 中，下一次读取路由表时一起生效，见 loadTable，Batch 执行期间在 Batch 结束时统一构建并替换，见 Batch
+// removePath 不为空时，删除 removeMethods 中各个 Method 的该路由
+func (r *router) update(removePath string, removeMethods []string, change func(t *routeTable) error) error {
+	root := r.root()
+
+	if root.built && root.batch {
+		return r.stage(removePath, removeMethods, change)
+	}
+
+	t := r.currentTable()
+	if root.built || removePath != "" {
+		t = t.clone(removePath, removeMethods)
+	}
+
+	if change != nil {
+		if err := change(t); err != nil {
+			return err
+		}
+	}
+
+	if !root.built {
+		r.table.Store(t)
+		return nil
+	}
+
+	if errs := r.buildTable(t); len(errs) > 0 {
+		return joinErrors(errs)
+	}
+
+	if r.staged == nil {
+		root.stagedRouters = append(root.stagedRouters, r)
+	}
+	r.staged = t
+	root.pending.Store(true)
+
+	return nil
+}
+
+// updateAttrs 修改路由名称，元数据，超时时间，调用前需要持有默认路由表的 mu
+// 路由表有暂存的修改时直接修改暂存的路由表，不需要重新构建，例如 Build 之后 a.Get(...).Name(...).Meta(...) 只构建一次
+func (r *router) updateAttrs(change func(t *routeTable)) error {
+	root := r.root()
+
+	if root.built && !root.batch && r.staged != nil {
+		change(r.staged)
+		return nil
+	}
+
+	return r.update("", nil, func(t *routeTable) error {
+		change(t)
+		return nil
+	})
+}
+
+// loadTable 读取当前生效的路由表，有暂存的修改时先使其生效，见 update
+func (r *router) loadTable() *routeTable {
+	if root := r.root(); root.pending.Load() {
+		root.mu.Lock()
+		root.publish()
+		root.mu.Unlock()
+	}
+
+	return r.table.Load()
+}
+
+// publish 替换所有暂存的路由表，调用前需要持有默认路由表的 mu，只有默认路由表使用
+// 暂存之后修改的名称，元数据，超时时间需要重新生成路由信息
+func (r *router) publish() {
+	for _, sr := range r.stagedRouters {
+		t := sr.staged
+		for method, re := range t.routes {
+			sr.buildMatchedRoutes(t, method, re)
+		}
+
+		sr.table.Store(t)
+		sr.staged = nil
+	}
+
+	r.stagedRouters = nil
+	r.pending.Store(false)
+}
+
+// stage Batch 执行期间修改暂存的未 Build 的路由表，第一次修改时复制当前路由表，调用前需要持有默认路由表的 mu
+func (r *router) stage(removePath string, removeMethods []string, change func(t *routeTable) error) error {
+	t := r.staged
+	if t == nil || removePath != "" {
+		t = r.currentTable().clone(removePath, removeMethods)
+	}
+
+	if change != nil {
+		if err := change(t); err != nil {
+			return err
+		}
+	}
+
+	if r.staged == nil {
+		// Batch 失败时恢复
+		r.savedRegistered = make(map[string]bool, len(r.registered))
+		for key, value := range r.registered {
+			r.savedRegistered[key] = value
+		}
+
+		r.savedConditions = make(map[string]int, len(r.conditions))
+		for key, value := range r.conditions {
+			r.savedConditions[key] = value
+		}
+
+		root := r.root()
+		root.stagedRouters = append(root.stagedRouters, r)
+	}
+
+	r.staged = t
+
+	return nil
+}
+
+// currentTable 当前修改使用的路由表，Batch 执行期间为暂存的路由表，调用前需要持有默认路由表的 mu
+func (r *router) currentTable() *routeTable {
+	if r.staged != nil {
+		return r.staged
+	}
+
+	return r.table.Load()
+}
+
+// Batch 执行 f，其中注册，删除路由，设置名称，元数据，超时时间等修改在 f 返回后一次构建并整体替换
+// Build 之后注册带有元数据的路由时使用，避免路由生效时还没有元数据，同时只需要重新构建一次
+// f 返回错误或者构建失败时，f 中的所有修改都不会生效，返回该错误
+// f 执行期间其它 goroutine 的修改同样在 f 返回后生效，多个 Batch 依次执行，f 中不可以调用 Batch
+// Build 之前直接执行 f
+// 示例: a.Router().Batch(func() error { a.Get("/admin", handler).Meta("perm", "admin"); return nil })
+func (r *router) Batch(f func() error) error {
+	root := r.root()
+	root.batchMu.Lock()
+	defer root.batchMu.Unlock()
+
+	root.mu.Lock()
+	if !root.built {
+		root.mu.Unlock()
+		return f()
+	}
+	// 之前暂存的修改先生效，不受 f 的结果影响
+	root.publish()
+	root.batch = true
+	root.mu.Unlock()
+
+	err := f()
+
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	root.batch = false
+
+	routers := root.stagedRouters
+	root.stagedRouters = nil
+
+	if err == nil {
+		var errs []error
+		for _, sr := range routers {
+			errs = append(errs, sr.buildTable(sr.staged)...)
+		}
+		if len(errs) > 0 {
+			err = joinErrors(errs)
+		}
+	}
+
+	for _, sr := range routers {
+		if err == nil {
+			sr.table.Store(sr.staged)
+		} else {
+			sr.registered, sr.conditions = sr.savedRegistered, sr.savedConditions
+			sr.lastPath, sr.lastKeys = "", nil
+		}
+
+		sr.staged, sr.savedRegistered, sr.savedConditions = nil, nil, nil
+	}
+
+	return err
+}
+
+// buildTable 解析路由表中的所有路由，成功后为含有路由处理函数的节点生成路由信息
+func (r *router) buildTable(t *routeTable) []error {
+	var errs []error
+
+	for _, method := range sortMethods(t.methods()) {
+		re := t.routes[method]
+
+		if err := re.Build(r); err != nil {
+			var e *zeroapi.RouteError
+			if errors.As(err, &e) {
+				e.Method, e.Host = method, r.host
+			}
+			errs = append(errs, err)
+			continue
+		}
+
+		r.buildMatchedRoutes(t, method, re)
+	}
+
+	return errs
+}
+
+// Remove 删除路由，path 为路由全路径，包括前缀，与 Routes() 中的 Path 相同
+// method 为 zeroapi.MethodAny 时，删除该路径下所有已注册的 Method，包括自定义 Method 和只注册了带有匹配条件路由的 Method
+// 同时删除该路由上所有带有匹配条件的路由
+// Build 之后也可以调用，复制路由表删除路由并重新构建，所有 Method 一起生效，不影响正在处理的请求
+// 路由不存在时返回 *zeroapi.RouteError，可以使用 errors.Is(err, zeroapi.ErrRouteNotFound) 判断
+func (r *router) Remove(method, path string) error {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	methods := []string{method}
+	if method == zeroapi.MethodAny {
+		methods = r.pathMethods(path)
+	}
+
+	if len(methods) == 0 || !r.hasRoute(methods[0], path) {
+		return &zeroapi.RouteError{Method: method, Host: r.host, Path: path, Err: zeroapi.ErrRouteNotFound}
+	}
+
+	keys := make([]string, 0, len(methods))
+	for _, m := range methods {
+		keys = append(keys, metaKey(m, path))
+	}
+
+	err := r.update(path, methods, func(t *routeTable) error {
+		for _, key := range keys {
+			for k := range t.metas {
+				if k == key || strings.HasPrefix(k, key+"#") {
+					delete(t.metas, k)
+				}
+			}
+
+			for k := range t.timeouts {
+				if k == key || strings.HasPrefix(k, key+"#") {
+					delete(t.timeouts, k)
+				}
+			}
+		}
+
+		// 没有其它 Method 使用该路径时，同时删除路由名称
+		if len(r.pathMethods(path)) == len(methods) {
+			for name, p := range t.names {
+				if p == path {
+					delete(t.names, name)
+				}
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		delete(r.registered, key)
+		delete(r.conditions, key)
+	}
+
+	if r.lastPath == path {
+		r.lastPath = ""
+		r.lastKeys = nil
+	}
+
+	return nil
+}
+
+// hasRoute 是否注册了该路由，包括带有匹配条件的路由
+func (r *router) hasRoute(method, path string) bool {
+	key := metaKey(method, path)
+	return r.registered[key] || r.conditions[key] > 0
+}
+
+// pathMethods 注册了 path 的所有 Method，包括只注册了带有匹配条件路由的 Method，按照 sortMethods 排序
+func (r *router) pathMethods(path string) []string {
+	methods := make(map[string]bool)
+
+	for key := range r.registered {
+		if pathOfKey(key) == path {
+			methods[methodOfKey(key)] = true
+		}
+	}
+
+	for key, num := range r.conditions {
+		if num > 0 && pathOfKey(key) == path {
+			methods[methodOfKey(key)] = true
+		}
+	}
+
+	if len(methods) == 0 {
+		return nil
+	}
+
+	return sortMethods(methods)
+}
+
+// pathOfKey 从 Method + " " + 路由全路径 中获取路由全路径
+func pathOfKey(key string) string {
+	_, path, _ := strings.Cut(key, " ")
+	return path
+}
+
+// methodOfKey 从 Method + " " + 路由全路径 中获取 Method
+func methodOfKey(key string) string {
+	method, _, _ := strings.Cut(key, " ")
+	return method
+}
+
+// containsString s 中是否含有 v
+func containsString(s []string, v string) bool {
+	for _, item := range s {
+		if item == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func joinErrors(errs []error) error {
+	if len(errs) == 1 {
+		return errs[0]
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,384 @@
+package router_test
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func TestRouterRuntimeRegister(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/user/:id", emptyHandle)
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Register(zeroapi.MethodGet, "/plugin/:id(^\\d+$)", emptyHandle); err != nil {
+		t.Fatal(err)
+	}
+	r.Name("plugin")
+	r.Meta("perm", "admin")
+
+	route, dynamic := r.LookupRoute(zeroapi.MethodGet, "/plugin/1001")
+	if route == nil || dynamic["id"] != "1001" || route.Name() != "plugin" || route.Meta("perm") != "admin" {
+		t.Fatal("runtime register failed")
+	}
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/plugin/p1001"); handlers != nil {
+		t.Fatal("regexp should be built")
+	}
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/user/1001"); handlers == nil {
+		t.Fatal("existing route lost")
+	}
+
+	// 失败时路由表保持不变
+	err := r.Register(zeroapi.MethodGet, "/broken/:id|none|", emptyHandle)
+	if !errors.Is(err, zeroapi.ErrValidatorNotFound) {
+		t.Fatalf("invalid error: %v", err)
+	}
+	if err := r.Register(zeroapi.MethodGet, "/user/:name", emptyHandle); !errors.Is(err, zeroapi.ErrParamConflict) {
+		t.Fatalf("invalid error: %v", err)
+	}
+	if len(r.Routes()) != 2 {
+		t.Fatalf("invalid routes: %d", len(r.Routes()))
+	}
+
+	// 运行时的错误不会被记录
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouterRuntimeRegisterChain(t *testing.T) {
+	a := zeroapp.NewApp()
+
+	// 每次构建路由表都会调用一次
+	var builds atomic.Int32
+	a.Router().RegisterRouterValidatorFactory("counted", func(args ...string) (zeroapi.RouterValidator, error) {
+		builds.Add(1)
+		return func(string) bool { return true }, nil
+	})
+	a.Get("/user/:id|counted(1)|", emptyHandle)
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 注册，名称，元数据，超时时间一起生效，只构建一次
+	builds.Store(0)
+	a.Get("/plugin/:id", emptyHandle).Name("plugin").Meta("perm", "admin").Timeout(time.Second)
+
+	route, _ := r.LookupRoute(zeroapi.MethodGet, "/plugin/1001")
+	if route == nil || route.Name() != "plugin" || route.Meta("perm") != "admin" || route.Timeout() != time.Second {
+		t.Fatal("runtime register chain failed")
+	}
+	if n := builds.Load(); n != 1 {
+		t.Fatalf("invalid builds: %d", n)
+	}
+
+	// 生效之后的修改同样可以使用
+	a.Meta("tag", "plugin")
+	if route, _ = r.LookupRoute(zeroapi.MethodGet, "/plugin/1001"); route == nil || route.Meta("tag") != "plugin" || route.Meta("perm") != "admin" {
+		t.Fatal("meta after publish failed")
+	}
+}
+
+func TestRouterRuntimeGroupTimeout(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/user", emptyHandle)
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 路由生效时已经带有组路由级别超时时间
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for {
+			if route, _ := r.LookupRoute(zeroapi.MethodGet, "/report/daily"); route != nil && route.Timeout() != time.Second {
+				t.Error("route is visible without group timeout")
+				return
+			}
+
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+	}()
+
+	g := a.Group("/report").UseTimeout(time.Second)
+	for i := 0; i < 20; i++ {
+		g.Get("/daily", emptyHandle)
+		if err := r.Remove(zeroapi.MethodGet, "/report/daily"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	close(done)
+	wg.Wait()
+}
+
+func TestRouterRemoveAnyCustomMethod(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Handle("PURGE", "/cache", emptyHandle)
+	a.Get("/cache", emptyHandle)
+	a.When(zeroapi.Headers("X-Debug", "")).Handle("REPORT", "/cache", emptyHandle)
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 所有 Method 一起删除，不会出现只删除了一部分的路由表
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for {
+			if allowed := r.Allowed("/cache"); allowed != nil && len(allowed) != 3 {
+				t.Errorf("half removed: %v", allowed)
+				return
+			}
+
+			select {
+			case <-done:
+				return
+			default:
+			}
+		}
+	}()
+
+	if err := r.Remove(zeroapi.MethodAny, "/cache"); err != nil {
+		t.Fatal(err)
+	}
+
+	close(done)
+	wg.Wait()
+
+	if allowed := r.Allowed("/cache"); allowed != nil {
+		t.Fatalf("invalid allowed: %v", allowed)
+	}
+	if err := r.Remove(zeroapi.MethodAny, "/cache"); !errors.Is(err, zeroapi.ErrRouteNotFound) {
+		t.Fatalf("invalid error: %v", err)
+	}
+}
+
+func TestRouterBatch(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/user/:id", emptyHandle)
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	err := r.Batch(func() error {
+		a.Get("/admin", emptyHandle).Name("admin").Meta("perm", "admin")
+		a.Host("api.example.com").Get("/admin", emptyHandle).Meta("perm", "api")
+
+		// f 返回之前路由不会生效
+		if route, _ := r.LookupRoute(zeroapi.MethodGet, "/admin"); route != nil {
+			t.Error("route should not be visible before the batch returns")
+		}
+
+		return r.Remove(zeroapi.MethodGet, "/user/:id")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if route, _ := r.LookupRoute(zeroapi.MethodGet, "/admin"); route == nil || route.Name() != "admin" || route.Meta("perm") != "admin" {
+		t.Fatal("batch register failed")
+	}
+	if route, _ := r.Host("api.example.com").LookupRoute(zeroapi.MethodGet, "/admin"); route == nil || route.Meta("perm") != "api" {
+		t.Fatal("batch register on host failed")
+	}
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/user/1001"); handlers != nil {
+		t.Fatal("batch remove failed")
+	}
+
+	// 失败时所有修改都不会生效
+	err = r.Batch(func() error {
+		a.Get("/plugin", emptyHandle).Meta("perm", "admin")
+		return r.Register(zeroapi.MethodGet, "/broken/:id|none|", emptyHandle)
+	})
+	if !errors.Is(err, zeroapi.ErrValidatorNotFound) {
+		t.Fatalf("invalid error: %v", err)
+	}
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/plugin"); handlers != nil {
+		t.Fatal("failed batch should be discarded")
+	}
+
+	// 可以重新注册
+	if err := r.Register(zeroapi.MethodGet, "/plugin", emptyHandle); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestRouterConcurrentSettings(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/user/:id|isNum|", emptyHandle).Name("user")
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+
+		for i := 0; i < 100; i++ {
+			_ = r.Mode()
+			_, _ = r.URL("user", map[string]string{"id": "1"}, nil)
+			_ = r.Validators()
+		}
+	}()
+
+	for i := 0; i < 100; i++ {
+		r.SetMode(r.Mode() | zeroapi.RouterModeRedirectTrailingSlash)
+		r.RegisterRouterValidator(fmt.Sprintf("v%d", i), less4)
+	}
+
+	wg.Wait()
+}
+
+func TestRouterRemove(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/user/:id", emptyHandle).Name("user")
+	a.Put("/user/:id", emptyHandle)
+	a.Get("/files/*filepath", emptyHandle).Name("files")
+	a.Get("/draft", emptyHandle)
+
+	r := a.Router()
+
+	// Build 之前也可以删除
+	if err := r.Remove(zeroapi.MethodGet, "/draft"); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Remove(zeroapi.MethodGet, "/user/:id"); err != nil {
+		t.Fatal(err)
+	}
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/user/1001"); handlers != nil {
+		t.Fatal("remove failed")
+	}
+	if handlers, _ := r.Lookup(zeroapi.MethodPut, "/user/1001"); handlers == nil {
+		t.Fatal("put route lost")
+	}
+	if _, err := r.URL("user", map[string]string{"id": "1001"}, nil); err != nil {
+		t.Fatal("name should be kept while PUT uses the path")
+	}
+
+	if err := r.Remove(zeroapi.MethodGet, "/files/*filepath"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.URL("files", nil, nil); err == nil {
+		t.Fatal("name should be removed")
+	}
+
+	err := r.Remove(zeroapi.MethodGet, "/draft")
+	if !errors.Is(err, zeroapi.ErrRouteNotFound) {
+		t.Fatalf("invalid error: %v", err)
+	}
+
+	// 删除后可以重新注册
+	if err := r.Register(zeroapi.MethodGet, "/user/:name", emptyHandle); err != nil {
+		t.Fatal(err)
+	}
+	if _, dynamic := r.Lookup(zeroapi.MethodGet, "/user/yaha"); dynamic["name"] != "yaha" {
+		t.Fatal("register after remove failed")
+	}
+}
+
+func TestRouterRuntimeConcurrent(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/user/:id", emptyHandle)
+
+	r := a.Router()
+	host := r.Host("{tenant}.example.com")
+	if err := host.Register(zeroapi.MethodGet, "/tenant", emptyHandle); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	done := make(chan struct{})
+
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-done:
+					return
+				default:
+				}
+
+				if route, _ := r.LookupRoute(zeroapi.MethodGet, "/user/1001"); route == nil {
+					t.Error("existing route lost")
+					return
+				}
+
+				h, _ := r.MatchHost("yaha.example.com")
+				h.LookupRoute(zeroapi.MethodGet, "/tenant")
+				r.LookupRoute(zeroapi.MethodGet, "/plugin/1/list")
+				r.Allowed("/plugin/1/list")
+				r.Routes()
+			}
+		}()
+	}
+
+	for i := 0; i < 50; i++ {
+		path := fmt.Sprintf("/plugin/%d/:name", i)
+
+		if err := r.Register(zeroapi.MethodGet, path, emptyHandle); err != nil {
+			t.Fatal(err)
+		}
+		r.Meta("index", i)
+
+		if err := host.Register(zeroapi.MethodGet, path, emptyHandle); err != nil {
+			t.Fatal(err)
+		}
+
+		if i%2 == 0 {
+			if err := r.Remove(zeroapi.MethodGet, path); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+
+	close(done)
+	wg.Wait()
+
+	if len(r.Routes()) != 1+1+25+50 {
+		t.Fatalf("invalid routes: %d", len(r.Routes()))
+	}
+}
@@ -0,0 +1,34 @@
+package router
+
+import (
+	"time"
+)
+
+// Timeout 为最近一次注册的路由设置超时时间，<= 0 时不限制
+// 超时后请求的 context 被取消，并返回 503，见 app.WithTimeoutStatus
+func (r *router) Timeout(timeout time.Duration) bool {
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	if r.lastPath == "" {
+		return false
+	}
+
+	err := r.updateAttrs(func(t *routeTable) {
+		for _, k := range r.lastKeys {
+			if timeout > 0 {
+				t.timeouts[k] = timeout
+			} else {
+				delete(t.timeouts, k)
+			}
+		}
+	})
+
+	return err == nil
+}
+
+// Timeout 超时时间，未设置时为 0
+func (mr *matchedRoute) Timeout() time.Duration {
+	return mr.timeout
+}
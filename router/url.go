@@ -0,0 +1,176 @@
+package router
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// URL 根据路由名称生成路径，填充动态参数与通配符，并使用正则表达式和验证函数检查参数值
+// name: 路由名称，见 Name
+// params: 动态参数，未命名通配符的参数名为 "*"，未提供的可选参数会被省略
+// query: 查询参数，可以为 nil
+//
+// 示例:
+// 路由: /blog/:id(^\d+$)，名称: blog
+// URL("blog", map[string]string{"id": "1001"}, nil) -> /blog/1001
+func (r *router) URL(name string, params map[string]string, query url.Values) (string, error) {
+	fullPath, exist := r.loadTable().names[name]
+	if !exist {
+		return "", fmt.Errorf("route name \"%s\" not found", name)
+	}
+
+	segments, err := r.urlSegments(fullPath)
+	if err != nil {
+		return "", fmt.Errorf("route \"%s\": %w", name, err)
+	}
+
+	var b strings.Builder
+
+	// skipped 已经跳过了可选参数
+	skipped := false
+
+	for _, segment := range segments {
+		node := segment.node
+
+		if node == nil {
+			b.WriteString(segment.path)
+			continue
+		}
+
+		if node.IsWildcard() {
+			value := params[node.dynamicName]
+			if value == "" && node.notEmpty {
+				return "", fmt.Errorf("route \"%s\": missing param \"%s\"", name, node.dynamicName)
+			}
+			if value != "" && !node.checkDynamicValueValid(value) {
+				return "", fmt.Errorf("route \"%s\": invalid value \"%s\" for param \"%s\"", name, value, node.dynamicName)
+			}
+
+			b.WriteString("/")
+			b.WriteString(escapeWildcard(value))
+			break
+		}
+
+		if segment.optional {
+			if params[node.dynamicName] == "" {
+				// 可选参数未提供时省略该片段
+				skipped = true
+				continue
+			}
+
+			if skipped {
+				return "", fmt.Errorf("route \"%s\": optional param \"%s\" requires the previous optional params", name, node.dynamicName)
+			}
+		}
+
+		b.WriteString("/")
+
+		if !node.IsMultiple() {
+			value, err := paramValue(name, node, params)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+			continue
+		}
+
+		// /:name.:ext
+		for _, part := range node.parts {
+			if part.node == nil {
+				b.WriteString(part.literal)
+				continue
+			}
+
+			value, err := paramValue(name, part.node, params)
+			if err != nil {
+				return "", err
+			}
+			b.WriteString(value)
+		}
+	}
+
+	if b.Len() == 0 {
+		b.WriteString("/")
+	}
+
+	if len(query) > 0 {
+		b.WriteString("?")
+		b.WriteString(query.Encode())
+	}
+
+	return b.String(), nil
+}
+
+// urlSegment URL 使用的已解析的路径片段
+type urlSegment struct {
+	// path 静态片段的路径
+	path string
+
+	// optional 是否为可选参数
+	optional bool
+
+	// node 解析后的动态参数或者通配符节点，静态片段为 nil
+	node *routeNode
+}
+
+// urlSegments 解析路由全路径，编译正则表达式和验证函数，结果按照全路径缓存
+// 注册验证函数时清空缓存，见 RegisterRouterValidator
+func (r *router) urlSegments(fullPath string) ([]urlSegment, error) {
+	root := r.root()
+	if segments, exist := root.urls.Load(fullPath); exist {
+		return segments.([]urlSegment), nil
+	}
+
+	var segments []urlSegment
+
+	for _, path := range buildPath(fullPath) {
+		path, isOptional, _ := parseOptional(path)
+		node := newChild(path).(*routeNode)
+
+		if !node.IsWildcard() && !node.IsDynamic() {
+			segments = append(segments, urlSegment{path: node.path})
+			continue
+		}
+
+		if err := node.Build(r); err != nil {
+			return nil, err
+		}
+
+		segments = append(segments, urlSegment{path: path, optional: isOptional, node: node})
+
+		if node.IsWildcard() {
+			break
+		}
+	}
+
+	root.urls.Store(fullPath, segments)
+
+	return segments, nil
+}
+
+// paramValue 获取动态参数的值并检查，返回编码后的结果
+func paramValue(name string, node *routeNode, params map[string]string) (string, error) {
+	value := params[node.dynamicName]
+	if value == "" {
+		return "", fmt.Errorf("route \"%s\": missing param \"%s\"", name, node.dynamicName)
+	}
+
+	if !node.checkDynamicValueValid(value) {
+		return "", fmt.Errorf("route \"%s\": invalid value \"%s\" for param \"%s\"", name, value, node.dynamicName)
+	}
+
+	return url.PathEscape(value), nil
+}
+
+// escapeWildcard 对通配符的值逐段编码，保留 "/"
+func escapeWildcard(value string) string {
+	value = strings.TrimPrefix(value, "/")
+
+	segments := strings.Split(value, "/")
+	for i, segment := range segments {
+		segments[i] = url.PathEscape(segment)
+	}
+
+	return strings.Join(segments, "/")
+}
@@ -0,0 +1,169 @@
+package router_test
+
+import (
+	"errors"
+	"net/url"
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func TestRouterURL(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+	r.RegisterRouterValidator("less4", less4)
+
+	a.Get("/", emptyHandle).Name("home")
+	a.Get("/blog/:id(^\\d+$)|less4|", emptyHandle).Name("blog")
+	a.Get("/static/*", emptyHandle).Name("static")
+
+	g := a.Group("/account")
+	g.Get("/:name/profile", emptyHandle).Name("profile")
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		params   map[string]string
+		query    url.Values
+		expected string
+	}{
+		{"home", nil, nil, "/"},
+		{"blog", map[string]string{"id": "101"}, nil, "/blog/101"},
+		{"blog", map[string]string{"id": "101"}, url.Values{"page": {"2"}}, "/blog/101?page=2"},
+		{"static", map[string]string{"*": "css/a b.css"}, nil, "/static/css/a%20b.css"},
+		{"profile", map[string]string{"name": "yaha/gama"}, nil, "/account/yaha%2Fgama/profile"},
+	}
+
+	for _, test := range tests {
+		u, err := r.URL(test.name, test.params, test.query)
+		if err != nil {
+			t.Fatalf("%s: %s", test.name, err.Error())
+		}
+		if u != test.expected {
+			t.Fatalf("%s, expected: %s, got: %s", test.name, test.expected, u)
+		}
+	}
+}
+
+func TestRouterURLFailed(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+	r.RegisterRouterValidator("less4", less4)
+
+	a.Get("/blog/:id(^\\d+$)|less4|", emptyHandle).Name("blog")
+
+	// 名称不可重复
+	if r.Name("blog") {
+		t.Fatal("duplicate name")
+	}
+
+	if _, err := r.URL("fake", nil, nil); err == nil {
+		t.Fatal("name not found")
+	}
+
+	if _, err := r.URL("blog", nil, nil); err == nil {
+		t.Fatal("missing param")
+	}
+
+	// 不能通过正则表达式
+	if _, err := r.URL("blog", map[string]string{"id": "abc"}, nil); err == nil {
+		t.Fatal("invalid regexp")
+	}
+
+	// 不能通过验证函数
+	if _, err := r.URL("blog", map[string]string{"id": "1001"}, nil); err == nil {
+		t.Fatal("invalid validator")
+	}
+}
+
+func TestRouterURLMultiple(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+
+	a.Get("/api/v:major(^\\d+$).:minor/files/:name.:ext", emptyHandle).Name("file")
+
+	u, err := r.URL("file", map[string]string{"major": "1", "minor": "2", "name": "a b", "ext": "txt"}, nil)
+	if err != nil {
+		t.Fatal(err.Error())
+	}
+
+	if u != "/api/v1.2/files/a%20b.txt" {
+		t.Fatalf("invalid url: %s", u)
+	}
+
+	if _, err := r.URL("file", map[string]string{"major": "x", "minor": "2", "name": "a", "ext": "txt"}, nil); err == nil {
+		t.Fatal("invalid regexp")
+	}
+}
+
+func TestRouterURLOptional(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+
+	a.Get("/archive/:year/:month?/:day?", emptyHandle).Name("archive")
+
+	tests := []struct {
+		params   map[string]string
+		expected string
+	}{
+		{map[string]string{"year": "2024"}, "/archive/2024"},
+		{map[string]string{"year": "2024", "month": "05"}, "/archive/2024/05"},
+		{map[string]string{"year": "2024", "month": "05", "day": "01"}, "/archive/2024/05/01"},
+	}
+
+	for _, test := range tests {
+		u, err := r.URL("archive", test.params, nil)
+		if err != nil {
+			t.Fatal(err.Error())
+		}
+		if u != test.expected {
+			t.Fatalf("expected: %s, got: %s", test.expected, u)
+		}
+	}
+
+	// 缺少前面的可选参数
+	if _, err := r.URL("archive", map[string]string{"year": "2024", "day": "01"}, nil); err == nil {
+		t.Fatal("missing previous optional param")
+	}
+}
+
+func TestRouterURLDuplicateName(t *testing.T) {
+	a := zeroapp.NewApp()
+	r := a.Router()
+
+	a.Get("/user/:id", emptyHandle).Name("user")
+	a.Get("/account/:id", emptyHandle).Name("user")
+
+	if err := r.Build(); !errors.Is(err, zeroapi.ErrDuplicateName) {
+		t.Fatalf("invalid error: %v", err)
+	}
+
+	if u, err := r.URL("user", map[string]string{"id": "1"}, nil); err != nil || u != "/user/1" {
+		t.Fatalf("first name should be kept: %s %v", u, err)
+	}
+}
+
+func BenchmarkRouterURL(b *testing.B) {
+	a := zeroapp.NewApp()
+	a.Get("/blog/:year(^\\d{4}$)/:slug|len(1,64)|", emptyHandle).Name("blog")
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		b.Fatal(err)
+	}
+
+	params := map[string]string{"year": "2024", "slug": "hello"}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := r.URL("blog", params, nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,202 @@
+package router
+
+import (
+	"encoding/base64"
+	"net"
+	"net/mail"
+	"strconv"
+	"time"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// defaultValidators 框架自带的验证函数，在所有 Router 中可用
+// 通过 RegisterRouterValidator 注册同名的验证函数可以覆盖
+var defaultValidators = map[string]zeroapi.RouterValidator{
+	"isNum":    isNum,
+	"isInt":    isInt,
+	"isFloat":  isFloat,
+	"isAlpha":  isAlpha,
+	"isAlnum":  isAlnum,
+	"isLower":  isLower,
+	"isUpper":  isUpper,
+	"isHex":    isHex,
+	"isUUID":   isUUID,
+	"isDate":   isDate,
+	"isEmail":  isEmail,
+	"isSlug":   isSlug,
+	"isBase64": isBase64,
+	"isIP":     isIP,
+	"isIPv4":   isIPv4,
+	"isIPv6":   isIPv6,
+}
+
+// isNum 全部由数字组成，例如 "1001"，"007"
+func isNum(s string) bool {
+	return isAll(s, isDigit)
+}
+
+// isInt 整数，可以带有正负号，例如 "-10"，"+10"，"10"
+func isInt(s string) bool {
+	_, err := strconv.ParseInt(s, 10, 64)
+	return err == nil
+}
+
+// isFloat 浮点数，例如 "3.14"，"-1e10"
+func isFloat(s string) bool {
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
+
+// isAlpha 全部由英文字母组成
+func isAlpha(s string) bool {
+	return isAll(s, func(c byte) bool { return isLowerChar(c) || isUpperChar(c) })
+}
+
+// isAlnum 全部由英文字母和数字组成
+func isAlnum(s string) bool {
+	return isAll(s, func(c byte) bool { return isLowerChar(c) || isUpperChar(c) || isDigit(c) })
+}
+
+// isLower 全部由小写英文字母组成
+func isLower(s string) bool {
+	return isAll(s, isLowerChar)
+}
+
+// isUpper 全部由大写英文字母组成
+func isUpper(s string) bool {
+	return isAll(s, isUpperChar)
+}
+
+// isHex 十六进制字符串，例如 "ff00"，"1A2B"
+func isHex(s string) bool {
+	return isAll(s, isHexChar)
+}
+
+// isUUID UUID 格式，例如 "123e4567-e89b-12d3-a456-426614174000"
+func isUUID(s string) bool {
+	if len(s) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if s[i] != '-' {
+				return false
+			}
+		default:
+			if !isHexChar(s[i]) {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+// isDate 日期，格式为 YYYY-MM-DD，例如 "2024-05-01"
+func isDate(s string) bool {
+	_, err := time.Parse("2006-01-02", s)
+	return err == nil
+}
+
+// isEmail 邮箱地址，例如 "yaha@example.com"
+func isEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	return err == nil && addr.Address == s
+}
+
+// isSlug 由小写英文字母，数字和 "-" 组成，"-" 不能在开头，结尾，也不能连续出现，例如 "hello-world-2024"
+func isSlug(s string) bool {
+	if s == "" || s[0] == '-' || s[len(s)-1] == '-' {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '-' {
+			if s[i-1] == '-' {
+				return false
+			}
+			continue
+		}
+
+		if !isLowerChar(c) && !isDigit(c) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isBase64 base64 编码，支持标准编码与 URL 编码，例如 "aGVsbG8="，"aGVsbG8"
+func isBase64(s string) bool {
+	if s == "" {
+		return false
+	}
+
+	for _, encoding := range []*base64.Encoding{base64.StdEncoding, base64.URLEncoding, base64.RawStdEncoding, base64.RawURLEncoding} {
+		if _, err := encoding.DecodeString(s); err == nil {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isIP IPv4 或者 IPv6 地址
+func isIP(s string) bool {
+	return net.ParseIP(s) != nil
+}
+
+// isIPv4 IPv4 地址，例如 "192.168.1.8"
+func isIPv4(s string) bool {
+	ip := net.ParseIP(s)
+	return ip != nil && ip.To4() != nil && !containsByte(s, ':')
+}
+
+// isIPv6 IPv6 地址，例如 "::1"
+func isIPv6(s string) bool {
+	return net.ParseIP(s) != nil && containsByte(s, ':')
+}
+
+func isAll(s string, f func(c byte) bool) bool {
+	if s == "" {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if !f(s[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func containsByte(s string, b byte) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == b {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+func isLowerChar(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+func isUpperChar(c byte) bool {
+	return 'A' <= c && c <= 'Z'
+}
+
+func isHexChar(c byte) bool {
+	return isDigit(c) || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
+}
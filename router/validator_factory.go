@@ -0,0 +1,214 @@
+package router
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// defaultValidatorFactories 框架自带的带参数的验证函数，在所有 Router 中可用
+// 通过 RegisterRouterValidatorFactory 注册同名的生成函数可以覆盖
+var defaultValidatorFactories = map[string]zeroapi.RouterValidatorFactory{
+	"len":    lenFactory,
+	"minLen": minLenFactory,
+	"maxLen": maxLenFactory,
+	"range":  rangeFactory,
+	"oneof":  oneofFactory,
+	"prefix": prefixFactory,
+	"suffix": suffixFactory,
+}
+
+// resolveValidator 根据名称获取验证函数
+// 示例:
+// isNum -> router.Validator("isNum")
+// len(3,16) -> router.ValidatorFactory("len")("3", "16")
+func resolveValidator(router zeroapi.Router, token string) (zeroapi.RouterValidator, error) {
+	pos := strings.IndexByte(token, '(')
+	if pos == -1 {
+		if validator := router.Validator(token); validator != nil {
+			return validator, nil
+		}
+
+		return nil, fmt.Errorf("%w: \"%s\"", zeroapi.ErrValidatorNotFound, token)
+	}
+
+	if token[len(token)-1] != ')' {
+		return nil, fmt.Errorf("%w: validator \"%s\" missing \")\"", zeroapi.ErrUnbalanced, token)
+	}
+
+	name := token[:pos]
+	factory := router.ValidatorFactory(name)
+	if factory == nil {
+		return nil, fmt.Errorf("%w: \"%s\"", zeroapi.ErrValidatorNotFound, token)
+	}
+
+	var args []string
+	if s := token[pos+1 : len(token)-1]; strings.TrimSpace(s) != "" {
+		args = strings.Split(s, ",")
+		for i, arg := range args {
+			args[i] = strings.TrimSpace(arg)
+		}
+	}
+
+	validator, err := factory(args...)
+	if err != nil {
+		return nil, fmt.Errorf("%w: \"%s\": %s", zeroapi.ErrInvalidValidator, token, err.Error())
+	}
+
+	return validator, nil
+}
+
+// lenFactory 字符数量(不是字节数量)
+// len(n): 等于 n
+// len(min,max): 在 [min, max] 之间
+func lenFactory(args ...string) (zeroapi.RouterValidator, error) {
+	if len(args) == 1 {
+		n, err := parseLength(args[0])
+		if err != nil {
+			return nil, err
+		}
+
+		return func(s string) bool {
+			return utf8.RuneCountInString(s) == n
+		}, nil
+	}
+
+	if len(args) != 2 {
+		return nil, fmt.Errorf("expected 1 or 2 arguments, got %d", len(args))
+	}
+
+	min, err := parseLength(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	max, err := parseLength(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	if min > max {
+		return nil, fmt.Errorf("min %d is greater than max %d", min, max)
+	}
+
+	return func(s string) bool {
+		n := utf8.RuneCountInString(s)
+		return min <= n && n <= max
+	}, nil
+}
+
+// minLenFactory minLen(n): 字符数量不少于 n
+func minLenFactory(args ...string) (zeroapi.RouterValidator, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+
+	min, err := parseLength(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return func(s string) bool {
+		return utf8.RuneCountInString(s) >= min
+	}, nil
+}
+
+// maxLenFactory maxLen(n): 字符数量不多于 n
+func maxLenFactory(args ...string) (zeroapi.RouterValidator, error) {
+	if len(args) != 1 {
+		return nil, fmt.Errorf("expected 1 argument, got %d", len(args))
+	}
+
+	max, err := parseLength(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return func(s string) bool {
+		return utf8.RuneCountInString(s) <= max
+	}, nil
+}
+
+// rangeFactory range(min,max): 整数，且在 [min, max] 之间
+func rangeFactory(args ...string) (zeroapi.RouterValidator, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("expected 2 arguments, got %d", len(args))
+	}
+
+	min, err := strconv.ParseInt(args[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid min \"%s\"", args[0])
+	}
+
+	max, err := strconv.ParseInt(args[1], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid max \"%s\"", args[1])
+	}
+
+	if min > max {
+		return nil, fmt.Errorf("min %d is greater than max %d", min, max)
+	}
+
+	return func(s string) bool {
+		n, err := strconv.ParseInt(s, 10, 64)
+		return err == nil && min <= n && n <= max
+	}, nil
+}
+
+// oneofFactory oneof(a,b,...): 等于其中一个值
+func oneofFactory(args ...string) (zeroapi.RouterValidator, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("expected at least 1 argument")
+	}
+
+	values := make(map[string]struct{}, len(args))
+	for _, arg := range args {
+		if arg == "" {
+			return nil, fmt.Errorf("empty argument")
+		}
+		values[arg] = struct{}{}
+	}
+
+	return func(s string) bool {
+		_, exist := values[s]
+		return exist
+	}, nil
+}
+
+// prefixFactory prefix(p): 以 p 开头，且不只有 p
+func prefixFactory(args ...string) (zeroapi.RouterValidator, error) {
+	if len(args) != 1 || args[0] == "" {
+		return nil, fmt.Errorf("expected 1 non-empty argument")
+	}
+
+	prefix := args[0]
+
+	return func(s string) bool {
+		return len(s) > len(prefix) && strings.HasPrefix(s, prefix)
+	}, nil
+}
+
+// suffixFactory suffix(p): 以 p 结尾，且不只有 p
+func suffixFactory(args ...string) (zeroapi.RouterValidator, error) {
+	if len(args) != 1 || args[0] == "" {
+		return nil, fmt.Errorf("expected 1 non-empty argument")
+	}
+
+	suffix := args[0]
+
+	return func(s string) bool {
+		return len(s) > len(suffix) && strings.HasSuffix(s, suffix)
+	}, nil
+}
+
+func parseLength(arg string) (int, error) {
+	n, err := strconv.Atoi(arg)
+	if err != nil || n < 0 {
+		return 0, fmt.Errorf("invalid length \"%s\"", arg)
+	}
+
+	return n, nil
+}
@@ -0,0 +1,99 @@
+package router_test
+
+import (
+	"errors"
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func TestValidatorFactory(t *testing.T) {
+	r := zeroapp.NewApp().Router()
+
+	r.Register(zeroapi.MethodGet, "/user/:name|len(3,8)|", emptyHandle)
+	r.Register(zeroapi.MethodGet, "/code/:code|len(4)|", emptyHandle)
+	r.Register(zeroapi.MethodGet, "/page/:page|range(1,9999)|", emptyHandle)
+	r.Register(zeroapi.MethodGet, "/sort/:order|oneof(asc, desc)|", emptyHandle)
+	r.Register(zeroapi.MethodGet, "/account/:id|prefix(usr_)|maxLen(10)|", emptyHandle)
+	r.Register(zeroapi.MethodGet, "/file/:name|suffix(.md)|minLen(4)|", emptyHandle)
+
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	valid := []string{"/user/yaha", "/user/中文名字", "/code/abcd", "/page/1", "/page/9999", "/sort/asc", "/sort/desc", "/account/usr_1001", "/file/a.md"}
+	for _, path := range valid {
+		if handlers, _ := r.Lookup(zeroapi.MethodGet, path); handlers == nil {
+			t.Fatalf("%s: not found", path)
+		}
+	}
+
+	invalid := []string{"/user/ya", "/user/yahayahay", "/code/abc", "/page/0", "/page/10000", "/page/abc", "/sort/up", "/account/usr_", "/account/1001", "/account/usr_1001001", "/file/.md", "/file/a.txt"}
+	for _, path := range invalid {
+		if handlers, _ := r.Lookup(zeroapi.MethodGet, path); handlers != nil {
+			t.Fatalf("%s: should not match", path)
+		}
+	}
+}
+
+func TestValidatorFactoryInvalidArgs(t *testing.T) {
+	paths := []string{
+		"/user/:name|len()|",
+		"/user/:name|len(a)|",
+		"/user/:name|len(8,3)|",
+		"/user/:name|len(1,2,3)|",
+		"/page/:page|range(1)|",
+		"/page/:page|range(a,b)|",
+		"/sort/:order|oneof()|",
+		"/account/:id|prefix()|",
+		"/account/:id|unknown(1)|",
+	}
+
+	for _, path := range paths {
+		r := zeroapp.NewApp().Router()
+		r.Register(zeroapi.MethodGet, path, emptyHandle)
+
+		if r.Build() == nil {
+			t.Fatalf("%s: should fail", path)
+		}
+	}
+}
+
+func TestRegisterRouterValidatorFactory(t *testing.T) {
+	r := zeroapp.NewApp().Router()
+
+	r.RegisterRouterValidatorFactory("eq", func(args ...string) (zeroapi.RouterValidator, error) {
+		if len(args) != 1 {
+			return nil, errors.New("expected 1 argument")
+		}
+		return func(s string) bool { return s == args[0] }, nil
+	})
+
+	if r.ValidatorFactory("eq") == nil || r.ValidatorFactory("len") == nil {
+		t.Fatal("factory not found")
+	}
+
+	r.Register(zeroapi.MethodGet, "/user/:name|eq(yaha)|", emptyHandle)
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/user/yaha"); handlers == nil {
+		t.Fatal("lookup failed")
+	}
+
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/user/gama"); handlers != nil {
+		t.Fatal("should not match")
+	}
+
+	found := false
+	for _, name := range r.Validators() {
+		if name == "eq()" {
+			found = true
+		}
+	}
+	if !found {
+		t.Fatal("eq() not in validators")
+	}
+}
@@ -0,0 +1,102 @@
+package router_test
+
+import (
+	"sort"
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func TestDefaultValidators(t *testing.T) {
+	r := zeroapp.NewApp().Router()
+
+	tests := map[string]struct {
+		valid   []string
+		invalid []string
+	}{
+		"isNum":    {[]string{"0", "1001", "007"}, []string{"", "-1", "1.5", "a1"}},
+		"isInt":    {[]string{"0", "-10", "+10", "1001"}, []string{"", "1.5", "a", "99999999999999999999"}},
+		"isFloat":  {[]string{"0", "3.14", "-1e10", "10"}, []string{"", "a", "1.2.3"}},
+		"isAlpha":  {[]string{"abc", "ABC", "aBc"}, []string{"", "a1", "a-b"}},
+		"isAlnum":  {[]string{"abc", "a1", "007"}, []string{"", "a-1", "a_1"}},
+		"isLower":  {[]string{"abc"}, []string{"", "aBc", "a1"}},
+		"isUpper":  {[]string{"ABC"}, []string{"", "AbC", "A1"}},
+		"isHex":    {[]string{"ff00", "1A2B", "0"}, []string{"", "fg", "0x10"}},
+		"isUUID":   {[]string{"123e4567-e89b-12d3-a456-426614174000"}, []string{"", "123e4567e89b12d3a456426614174000", "123e4567-e89b-12d3-a456-42661417400g"}},
+		"isDate":   {[]string{"2024-05-01", "2024-02-29"}, []string{"", "2024-5-1", "2023-02-29", "2024/05/01"}},
+		"isEmail":  {[]string{"yaha@example.com", "a.b+c@d.io"}, []string{"", "yaha", "Yaha <yaha@example.com>", "@example.com"}},
+		"isSlug":   {[]string{"hello", "hello-world-2024"}, []string{"", "-hello", "hello-", "hello--world", "Hello"}},
+		"isBase64": {[]string{"aGVsbG8=", "aGVsbG8", "_-8="}, []string{"", "aGVsbG8===", "a!b"}},
+		"isIP":     {[]string{"192.168.1.8", "::1", "2001:db8::1"}, []string{"", "256.0.0.1", "localhost"}},
+		"isIPv4":   {[]string{"192.168.1.8", "127.0.0.1"}, []string{"", "::1", "::ffff:127.0.0.1"}},
+		"isIPv6":   {[]string{"::1", "2001:db8::1", "::ffff:127.0.0.1"}, []string{"", "192.168.1.8"}},
+	}
+
+	for name, test := range tests {
+		validator := r.Validator(name)
+		if validator == nil {
+			t.Fatalf("validator %s not found", name)
+		}
+
+		for _, s := range test.valid {
+			if !validator(s) {
+				t.Fatalf("%s: \"%s\" should be valid", name, s)
+			}
+		}
+
+		for _, s := range test.invalid {
+			if validator(s) {
+				t.Fatalf("%s: \"%s\" should be invalid", name, s)
+			}
+		}
+	}
+}
+
+func TestRouterValidators(t *testing.T) {
+	r := zeroapp.NewApp().Router()
+	r.RegisterRouterValidator("less4", less4)
+
+	names := r.Validators()
+	if !sort.StringsAreSorted(names) {
+		t.Fatalf("validators not sorted: %v", names)
+	}
+
+	expected := map[string]bool{"isNum": false, "isUUID": false, "less4": false}
+	for _, name := range names {
+		if _, exist := expected[name]; exist {
+			expected[name] = true
+		}
+	}
+
+	for name, exist := range expected {
+		if !exist {
+			t.Fatalf("validator %s not found in %v", name, names)
+		}
+	}
+}
+
+func TestRouterValidatorOverride(t *testing.T) {
+	r := zeroapp.NewApp().Router()
+
+	// 可以覆盖框架自带的验证函数
+	r.RegisterRouterValidator("isNum", func(s string) bool { return s == "one" })
+
+	r.Register(zeroapi.MethodGet, "/blog/:id|isNum|", emptyHandle)
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if handlers, _ := r.Lookup(zeroapi.MethodGet, "/blog/one"); handlers == nil {
+		t.Fatal("validator not overridden")
+	}
+}
+
+func TestRouterBuildUnknownValidator(t *testing.T) {
+	r := zeroapp.NewApp().Router()
+
+	r.Register(zeroapi.MethodGet, "/blog/:id|isNun|", emptyHandle)
+	if r.Build() == nil {
+		t.Fatal("unknown validator")
+	}
+}
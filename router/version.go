@@ -0,0 +1,95 @@
+package router
+
+import (
+	"strings"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// APIVersion 获取 API 版本对应的路由表，不存在时创建，version 为空时返回当前路由表
+// 版本号忽略开头的 "v"，例如 "v2" 与 "2" 相同
+// 版本路由表与所属的路由表共享域名，与默认路由表共享前缀，路由模式，验证函数
+func (r *router) APIVersion(version string) zeroapi.Router {
+	base := r.versionBase()
+
+	version = normalizeVersion(version)
+	if version == "" {
+		return base
+	}
+
+	root := r.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	versions := base.versionList()
+	for _, v := range versions {
+		if v.version == version {
+			return v
+		}
+	}
+
+	v := &router{
+		app:        root.app,
+		validators: root.validators,
+		registered: make(map[string]bool),
+		conditions: make(map[string]int),
+		parent:     base,
+		host:       base.host,
+		version:    version,
+
+		validatorFactories: root.validatorFactories,
+	}
+	v.table.Store(newRouteTable())
+
+	// 复制后替换，MatchAPIVersion 不需要加锁
+	versions = append(versions[:len(versions):len(versions)], v)
+	base.versions.Store(&versions)
+
+	return v
+}
+
+// APIVersionName 获取路由表对应的 API 版本，不是版本路由表时返回 ""
+func (r *router) APIVersionName() string {
+	return r.version
+}
+
+// MatchAPIVersion 获取 API 版本对应的路由表，不存在时返回 nil
+func (r *router) MatchAPIVersion(version string) zeroapi.Router {
+	version = normalizeVersion(version)
+
+	for _, v := range r.versionBase().versionList() {
+		if v.version == version {
+			return v
+		}
+	}
+
+	return nil
+}
+
+// versionBase 版本路由表所属的路由表，默认路由表或者域名路由表
+func (r *router) versionBase() *router {
+	if r.version != "" {
+		return r.parent
+	}
+
+	return r
+}
+
+// versionList 获取所有版本路由表
+func (r *router) versionList() []*router {
+	if versions := r.versions.Load(); versions != nil {
+		return *versions
+	}
+
+	return nil
+}
+
+// normalizeVersion 去除空白和开头的 "v"
+func normalizeVersion(version string) string {
+	version = strings.TrimSpace(version)
+	if len(version) > 1 && (version[0] == 'v' || version[0] == 'V') {
+		version = version[1:]
+	}
+
+	return version
+}
@@ -0,0 +1,67 @@
+package router_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func TestAPIVersion(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/users", emptyHandle)
+	a.APIVersion("v2").Use(emptyHandle).Get("/users", emptyHandle).Meta("deprecated", false)
+	a.Group("/api").APIVersion("2").Get("/users", emptyHandle)
+	a.Host("{tenant}.example.com").APIVersion("3").Get("/users", emptyHandle)
+
+	r := a.Router()
+	if err := r.Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	v2 := r.MatchAPIVersion("2")
+	if v2 == nil || v2 != r.APIVersion("V2") || v2.APIVersionName() != "2" {
+		t.Fatal("match version failed")
+	}
+	if r.MatchAPIVersion("3") != nil || r.APIVersion("") != r {
+		t.Fatal("version 3 only exists in host router")
+	}
+
+	route, _ := v2.LookupRoute(zeroapi.MethodGet, "/users")
+	if route == nil || route.APIVersion() != "2" || len(route.Handlers()) != 2 || route.Meta("deprecated") != false {
+		t.Fatal("lookup version route failed")
+	}
+	if handlers, _ := v2.Lookup(zeroapi.MethodGet, "/api/users"); len(handlers) != 1 {
+		t.Fatal("group version route failed")
+	}
+
+	host, _ := r.MatchHost("yaha.example.com")
+	if v3 := host.MatchAPIVersion("3"); v3 == nil || v3.HostPattern() != "{tenant}.example.com" {
+		t.Fatal("host version failed")
+	}
+
+	var versions []string
+	for _, info := range r.Routes() {
+		versions = append(versions, info.Host+"|"+info.Version+"|"+info.Path)
+	}
+	if s := strings.Join(versions, ","); s != "|2|/api/users,||/users,|2|/users,{tenant}.example.com|3|/users" {
+		t.Fatalf("invalid routes: %s", s)
+	}
+
+	var b bytes.Buffer
+	r.Dump(&b)
+	if !strings.Contains(b.String(), "GET v2\n") || !strings.Contains(b.String(), "GET {tenant}.example.com v3\n") {
+		t.Fatalf("invalid dump: %s", b.String())
+	}
+}
+
+func TestAPIVersionBuildFailed(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.APIVersion("2").Get("/users/:id|none|", emptyHandle)
+
+	if err := a.Router().Build(); err == nil || !strings.Contains(err.Error(), "none") {
+		t.Fatalf("invalid error: %v", err)
+	}
+}
@@ -3,6 +3,7 @@ package server
 import (
 	"net/http"
 	"os"
+	"strings"
 
 	zeroapi "github.com/zerogo-hub/zero-api"
 	zeroctx "github.com/zerogo-hub/zero-api/context"
@@ -42,9 +43,12 @@ func (s *server) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 			s.app.Logger().Errorf("%+v", p)
 		}
 
+		// RunEnd 结束后 ctx 会被释放，需要先获取 Writer
+		w := ctx.Response()
+
 		go ctx.RunEnd()
 
-		zeroctx.ReleaseWriter(ctx.Response())
+		zeroctx.ReleaseWriter(w)
 	}()
 
 	if s.app.MaxMemory() > 0 {
@@ -53,38 +57,255 @@ func (s *server) ServeHTTP(res http.ResponseWriter, req *http.Request) {
 
 	ctx.Reset(res, req)
 
+	// 匹配路由，应用级别中间件中可以通过 ctx.Route() 和 ctx.Dynamic() 获取匹配结果
+	method, path := ctx.Method(), s.requestPath(ctx.Request())
+	m := s.lookup(ctx, method, path)
+
+	// 匹配得到的动态参数，重新匹配时用于找出中间件设置的动态参数
+	var buf [8]zeroapi.Param
+	matched := append(buf[:0], *ctx.Params()...)
+
 	// 执行应用级别中间件
 	s.app.ExecuteMiddlewares(ctx)
 	if ctx.IsStopped() {
 		return
 	}
 
-	// 匹配路由
-	method := ctx.Method()
-	path := ctx.Request().URL.Path
-	handlers, dynamic := s.app.Router().Lookup(method, path)
-	if handlers == nil {
+	// 中间件修改了请求的 Method 或者路径，重新匹配
+	if method != ctx.Method() || path != s.requestPath(ctx.Request()) {
+		method, path = ctx.Method(), s.requestPath(ctx.Request())
+		m = s.rematch(ctx, matched, method, path)
+	}
+
+	router := m.router
+
+	if m.route == nil {
+		if m.notAcceptable {
+			ctx.NotAcceptable()
+			return
+		}
+
+		if s.redirectFixedPath(ctx, router, method, path) {
+			return
+		}
+
+		if method == zeroapi.MethodOptions && s.isMode(zeroapi.RouterModeAutoOptions) {
+			if allowed := router.Allowed(path); len(allowed) > 0 {
+				ctx.SetHeader("Allow", strings.Join(allowed, ", "))
+				ctx.SetHTTPCode(http.StatusNoContent)
+				return
+			}
+		}
+
+		if s.app.IsHandleMethodNotAllowed() {
+			// 路由存在但是匹配条件都不满足时，返回 404
+			if allowed := router.Allowed(path); len(allowed) > 0 && !containsMethod(allowed, method) {
+				s.methodNotAllowed(ctx, allowed)
+				return
+			}
+		}
+
 		ctx.NotFound()
 		return
 	}
 
-	if dynamic != nil {
-		ctx.SetDynamics(dynamic)
+	if m.isHead {
+		// 使用 GET 路由处理 HEAD 请求，不输出响应内容
+		w := &headWriter{ResponseWriter: ctx.Response().Writer()}
+		ctx.Response().SetWriter(w)
+		defer w.finish()
 	}
 
 	// 执行路由处理函数和路由级别中间件
-	for _, handler := range handlers {
-		if handler == nil {
+	if !s.runHandlers(ctx, m.route) {
+		return
+	}
+
+	ctx.RunAfter()
+}
+
+// rematch 应用级别中间件修改请求后重新匹配路由，matched 为第一次匹配得到的动态参数
+// 中间件通过 SetDynamic 新增或者修改的动态参数在重新匹配后保留，与路由参数同名时以中间件设置的值为准
+func (s *server) rematch(ctx zeroapi.Context, matched zeroapi.Params, method, path string) matchResult {
+	params := ctx.Params()
+
+	var buf [8]zeroapi.Param
+	current := append(buf[:0], *params...)
+
+	m := s.lookup(ctx, method, path)
+
+	for i, p := range current {
+		if i < len(matched) && matched[i] == p {
 			continue
 		}
 
-		handler(ctx)
-		if ctx.IsStopped() {
-			return
+		params.Set(p.Key, p.Value)
+	}
+
+	return m
+}
+
+// requestPath 用于匹配路由的路径，开启 zeroapi.RouterModeUseRawPath 时使用未解码的路径
+func (s *server) requestPath(req *http.Request) string {
+	if s.isMode(zeroapi.RouterModeUseRawPath) {
+		return req.URL.EscapedPath()
+	}
+
+	return req.URL.Path
+}
+
+// matchResult 路由匹配结果
+type matchResult struct {
+	// router 路由表，未匹配时用于重定向和 405 检查
+	router zeroapi.Router
+
+	// route 匹配成功的路由，未匹配时为 nil
+	route zeroapi.MatchedRoute
+
+	// isHead 为 true 表示使用 GET 路由处理 HEAD 请求
+	isHead bool
+
+	// notAcceptable 请求的 API 版本不存在，见 app.WithStrictAPIVersion
+	notAcceptable bool
+}
+
+// lookup 根据域名和 API 版本选择路由表并匹配路由，将匹配结果和动态参数设置到 ctx 中
+// 域名路由表未匹配时使用默认路由表，都未匹配时，域名路由表中没有该路径的路由才使用默认路由表的结果，用于重定向和 405 检查
+func (s *server) lookup(ctx zeroapi.Context, method, path string) matchResult {
+	root := s.app.Router()
+	router, hostDynamic := root.MatchHost(ctx.Host())
+
+	// 动态参数直接填充到 ctx 中，未匹配时保持为空
+	params := ctx.Params()
+	*params = (*params)[:0]
+
+	version, explicit := s.requestVersion(ctx)
+	strict := explicit && s.app.IsStrictAPIVersion()
+
+	m := s.lookupVersion(ctx, router, version, strict, method, path)
+
+	if m.route == nil && router != root {
+		fallback := s.lookupVersion(ctx, root, version, strict, method, path)
+		if fallback.route != nil || len(m.router.Allowed(path)) == 0 {
+			m = fallback
 		}
+
+		// 域名参数只用于域名路由
+		hostDynamic = nil
 	}
 
-	ctx.RunAfter()
+	if m.route != nil {
+		// 域名参数，与路径参数同名时以路径参数为准
+		for key, value := range hostDynamic {
+			if _, exist := params.Get(key); !exist {
+				*params = append(*params, zeroapi.Param{Key: key, Value: value})
+			}
+		}
+	}
+
+	ctx.SetRoute(m.route)
+
+	return m
+}
+
+// lookupVersion 在路由表及其版本路由表中匹配路由，动态参数添加到 ctx.Params() 中
+// 版本路由表未匹配时使用 router，strict 为 true 时只使用请求指定的版本，见 app.WithStrictAPIVersion
+func (s *server) lookupVersion(ctx zeroapi.Context, router zeroapi.Router, version string, strict bool, method, path string) matchResult {
+	m := matchResult{router: router}
+
+	if version != "" {
+		if vr := router.MatchAPIVersion(version); vr != nil {
+			m.router = vr
+			m.route, m.isHead = s.match(vr, ctx.Request(), method, path, ctx.Params())
+		} else if strict {
+			m.notAcceptable = true
+		}
+	}
+
+	if m.route == nil && !strict {
+		m.router = router
+		m.route, m.isHead = s.match(router, ctx.Request(), method, path, ctx.Params())
+	}
+
+	return m
+}
+
+// match 在路由表中匹配路由，动态参数添加到 params 中，isHead 为 true 表示使用 GET 路由处理 HEAD 请求
+// req 用于检查路由的匹配条件
+func (s *server) match(router zeroapi.Router, req *http.Request, method, path string, params *zeroapi.Params) (zeroapi.MatchedRoute, bool) {
+	route := router.LookupRouteParams(req, method, path, params)
+	if route == nil && method == zeroapi.MethodHead && s.isMode(zeroapi.RouterModeAutoHead) {
+		if route = router.LookupRouteParams(req, zeroapi.MethodGet, path, params); route != nil {
+			return route, true
+		}
+	}
+
+	return route, false
+}
+
+// redirectFixedPath 修正路径后能够匹配路由，则重定向到修正后的路径
+func (s *server) redirectFixedPath(ctx zeroapi.Context, router zeroapi.Router, method, path string) bool {
+	if !s.isMode(zeroapi.RouterModeRedirectTrailingSlash | zeroapi.RouterModeRedirectFixedPath) {
+		return false
+	}
+
+	fixed := router.FixPath(method, path)
+	if fixed == "" && method == zeroapi.MethodHead && s.isMode(zeroapi.RouterModeAutoHead) {
+		fixed = router.FixPath(zeroapi.MethodGet, path)
+	}
+	if fixed == "" {
+		return false
+	}
+
+	code := http.StatusPermanentRedirect
+	if method == zeroapi.MethodGet {
+		code = http.StatusMovedPermanently
+	}
+
+	if query := ctx.Request().URL.RawQuery; query != "" {
+		fixed += "?" + query
+	}
+
+	if err := ctx.Redirect(code, fixed); err != nil {
+		s.app.Logger().Errorf("redirect failed, err: %s", err.Error())
+	}
+
+	return true
+}
+
+func (s *server) isMode(mode int) bool {
+	return s.app.Router().Mode()&mode != 0
+}
+
+// containsMethod methods 中是否包含 method
+func containsMethod(methods []string, method string) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+
+	return false
+}
+
+// methodNotAllowed 返回 405，Allow 中列出能够匹配的 Method
+func (s *server) methodNotAllowed(ctx zeroapi.Context, allowed []string) {
+	ctx.SetHeader("Allow", strings.Join(allowed, ", "))
+
+	handler := s.app.MethodNotAllowedHandler()
+	if handler == nil {
+		ctx.MethodNotAllowed()
+		return
+	}
+
+	// 状态码延迟到处理函数写入响应内容时写入，处理函数中设置的响应头才会生效
+	w := &statusWriter{ResponseWriter: ctx.Response().Writer(), code: http.StatusMethodNotAllowed}
+	ctx.Response().SetWriter(w)
+
+	handler(ctx)
+
+	// 已经写入，只记录状态码
+	ctx.SetHTTPCode(w.finish())
 }
 
 // Start 根据配置调用 ListenAndServe 或者 ListenAndServeTLS，接收连接请求
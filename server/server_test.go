@@ -0,0 +1,823 @@
+package server_test
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+	zeroapp "github.com/zerogo-hub/zero-api/app"
+)
+
+func emptyHandle(zeroapi.Context) {}
+
+func serve(a zeroapi.App, method, target string) *httptest.ResponseRecorder {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(method, target, nil)
+	a.Server().ServeHTTP(res, req)
+	return res
+}
+
+func TestServerMethodNotAllowed(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/user/:id", emptyHandle)
+	a.Put("/user/:id", emptyHandle)
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	res := serve(a, zeroapi.MethodPost, "/user/1001")
+	if res.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+	if allow := res.Header().Get("Allow"); allow != "GET, PUT" {
+		t.Fatalf("invalid allow: %s", allow)
+	}
+
+	if res := serve(a, zeroapi.MethodPost, "/account"); res.Code != http.StatusNotFound {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+}
+
+func TestServerMethodNotAllowedHandler(t *testing.T) {
+	a := zeroapp.NewApp(zeroapp.WithMethodNotAllowedHandler(func(ctx zeroapi.Context) {
+		_, _ = ctx.Text("custom")
+	}))
+	a.Get("/user", emptyHandle)
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	res := serve(a, zeroapi.MethodPost, "/user")
+	if res.Code != http.StatusMethodNotAllowed || res.Body.String() != "custom" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Body.String())
+	}
+}
+
+func TestServerMethodNotAllowedHandlerHeader(t *testing.T) {
+	a := zeroapp.NewApp(zeroapp.WithMethodNotAllowedHandler(func(ctx zeroapi.Context) {
+		_, _ = ctx.JSON(map[string]string{"message": "method not allowed"})
+	}))
+	a.Get("/user", emptyHandle)
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 使用真实的服务器，httptest.ResponseRecorder 在写入状态码之后仍然可以修改响应头
+	ts := httptest.NewServer(a.Server())
+	defer ts.Close()
+
+	res, err := http.Post(ts.URL+"/user", "text/plain", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusMethodNotAllowed {
+		t.Fatalf("invalid code: %d", res.StatusCode)
+	}
+	if contentType := res.Header.Get("Content-Type"); contentType != "application/json;charset=utf-8" {
+		t.Fatalf("invalid content type: %s", contentType)
+	}
+	if allow := res.Header.Get("Allow"); allow != "GET" {
+		t.Fatalf("invalid allow: %s", allow)
+	}
+}
+
+func TestServerMethodNotAllowedDisabled(t *testing.T) {
+	a := zeroapp.NewApp(zeroapp.WithHandleMethodNotAllowed(false))
+	a.Get("/user", emptyHandle)
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := serve(a, zeroapi.MethodPost, "/user"); res.Code != http.StatusNotFound {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+}
+
+func TestServerAutoHead(t *testing.T) {
+	a := zeroapp.NewApp(zeroapp.WithAutoHeadOptions(true))
+	a.Get("/user", func(ctx zeroapi.Context) {
+		_, _ = ctx.Text("hello")
+	})
+	a.Get("/blog", emptyHandle)
+	a.Head("/blog", func(ctx zeroapi.Context) {
+		ctx.SetHeader("X-Head", "blog")
+	})
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	res := serve(a, zeroapi.MethodHead, "/user")
+	if res.Code != http.StatusOK || res.Body.Len() != 0 {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Body.String())
+	}
+	if length := res.Header().Get("Content-Length"); length != "5" {
+		t.Fatalf("invalid Content-Length: %s", length)
+	}
+
+	// 已注册的 HEAD 路由优先
+	if res := serve(a, zeroapi.MethodHead, "/blog"); res.Header().Get("X-Head") != "blog" {
+		t.Fatal("registered HEAD route not used")
+	}
+}
+
+func TestServerAutoOptions(t *testing.T) {
+	a := zeroapp.NewApp(zeroapp.WithAutoHeadOptions(true))
+	a.Get("/user", emptyHandle)
+	a.Post("/user", emptyHandle)
+	a.Get("/blog", emptyHandle)
+	a.Options("/blog", func(ctx zeroapi.Context) {
+		ctx.SetHeader("X-Options", "blog")
+	})
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	res := serve(a, zeroapi.MethodOptions, "/user")
+	if res.Code != http.StatusNoContent {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+	if allow := res.Header().Get("Allow"); allow != "GET, POST, HEAD, OPTIONS" {
+		t.Fatalf("invalid allow: %s", allow)
+	}
+
+	// 已注册的 OPTIONS 路由优先
+	if res := serve(a, zeroapi.MethodOptions, "/blog"); res.Header().Get("X-Options") != "blog" {
+		t.Fatal("registered OPTIONS route not used")
+	}
+
+	if res := serve(a, zeroapi.MethodOptions, "/account"); res.Code != http.StatusNotFound {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+}
+
+func TestServerAutoHeadOptionsDisabled(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/user", emptyHandle)
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := serve(a, zeroapi.MethodHead, "/user"); res.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+
+	if res := serve(a, zeroapi.MethodOptions, "/user"); res.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+}
+
+func TestServerRedirectFixedPath(t *testing.T) {
+	a := zeroapp.NewApp(zeroapp.WithRedirectTrailingSlash(true), zeroapp.WithRedirectFixedPath(true))
+	a.Get("/user", emptyHandle)
+	a.Post("/user", emptyHandle)
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	res := serve(a, zeroapi.MethodGet, "/user/?id=1")
+	if res.Code != http.StatusMovedPermanently || res.Header().Get("Location") != "/user?id=1" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Header().Get("Location"))
+	}
+
+	res = serve(a, zeroapi.MethodPost, "/a/..//user")
+	if res.Code != http.StatusPermanentRedirect || res.Header().Get("Location") != "/user" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Header().Get("Location"))
+	}
+}
+
+func TestServerDynamicTyped(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/item/:id/:price/:flag", func(ctx zeroapi.Context) {
+		if ctx.DynamicInt("id") != 1001 || ctx.DynamicInt64("id") != 1001 || ctx.DynamicUint64("id") != 1001 {
+			t.Error("invalid id")
+		}
+		if ctx.DynamicFloat64("price") != 9.5 || !ctx.DynamicBool("flag") {
+			t.Error("invalid price or flag")
+		}
+		if ctx.DynamicIntDefault("price", 7) != 7 || ctx.DynamicBoolDefault("none", true) != true {
+			t.Error("invalid default")
+		}
+		if ctx.DynamicDefault("none", "def") != "def" || ctx.DynamicDefault("id", "def") != "1001" {
+			t.Error("invalid string default")
+		}
+
+		if id, err := zeroapi.DynamicAs[uint16](ctx, "id"); err != nil || id != 1001 {
+			t.Errorf("invalid generic id: %d %v", id, err)
+		}
+		if _, err := zeroapi.DynamicAs[int8](ctx, "id"); err == nil {
+			t.Error("int8 should overflow")
+		}
+		if _, err := zeroapi.DynamicAs[int](ctx, "none"); err == nil {
+			t.Error("none should not exist")
+		}
+		if v := zeroapi.DynamicAsDefault[float32](ctx, "flag", 1.5); v != 1.5 {
+			t.Errorf("invalid generic default: %v", v)
+		}
+	})
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := serve(a, zeroapi.MethodGet, "/item/1001/9.5/true"); res.Code != http.StatusOK {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+}
+
+func TestServerDynamicEmpty(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/archive/:year/:month?", func(ctx zeroapi.Context) {
+		// 未提供的可选参数存在，值为空
+		if month, err := zeroapi.DynamicAs[string](ctx, "month"); err != nil || month != "" {
+			t.Errorf("invalid month: %s %v", month, err)
+		}
+		if _, err := zeroapi.DynamicAs[int](ctx, ":month"); err == nil || errors.Is(err, zeroapi.ErrDynamicNotFound) {
+			t.Errorf("empty month should fail to convert: %v", err)
+		}
+		if _, err := zeroapi.DynamicAs[int](ctx, "day"); !errors.Is(err, zeroapi.ErrDynamicNotFound) {
+			t.Errorf("day should not exist: %v", err)
+		}
+		if ctx.DynamicDefault("month", "01") != "" || ctx.DynamicIntDefault("month", 1) != 1 {
+			t.Error("invalid default")
+		}
+	})
+	a.Get("/files/*filepath", func(ctx zeroapi.Context) {
+		if filepath, err := zeroapi.DynamicAs[string](ctx, "filepath"); err != nil || filepath != "" {
+			t.Errorf("invalid filepath: %s %v", filepath, err)
+		}
+	})
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{"/archive/2024", "/files/"} {
+		if res := serve(a, zeroapi.MethodGet, target); res.Code != http.StatusOK {
+			t.Fatalf("%s: invalid code: %d", target, res.Code)
+		}
+	}
+}
+
+func TestServerHost(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/user/:id", func(ctx zeroapi.Context) {
+		_, _ = ctx.Text("default " + ctx.Dynamic("id"))
+	})
+	a.Get("/health", func(ctx zeroapi.Context) {
+		_, _ = ctx.Text("ok" + ctx.Dynamic("tenant"))
+	})
+	a.Host("{tenant}.example.com").Get("/user/:id", func(ctx zeroapi.Context) {
+		_, _ = ctx.Text(ctx.Dynamic("tenant") + " " + ctx.Dynamic("id"))
+	})
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]string{
+		"http://yaha.example.com/user/1001":      "yaha 1001",
+		"http://gama.example.com:8080/user/1002": "gama 1002",
+		"http://example.com/user/1003":           "default 1003",
+		"http://localhost/user/1004":             "default 1004",
+		// 域名路由表未匹配时使用默认路由表，不包含域名参数
+		"http://yaha.example.com/health": "ok",
+	}
+
+	for target, body := range tests {
+		res := serve(a, zeroapi.MethodGet, target)
+		if res.Code != http.StatusOK || res.Body.String() != body {
+			t.Fatalf("%s: invalid response: %d %s", target, res.Code, res.Body.String())
+		}
+	}
+
+	if res := serve(a, zeroapi.MethodGet, "http://yaha.example.com/account"); res.Code != http.StatusNotFound {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+	if res := serve(a, zeroapi.MethodPost, "http://yaha.example.com/health"); res.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+	if res := serve(a, zeroapi.MethodPost, "http://yaha.example.com/user/1001"); res.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+}
+
+func TestServerRouteMeta(t *testing.T) {
+	a := zeroapp.NewApp()
+
+	// 应用级别中间件根据路由元数据检查权限
+	a.Use(func(ctx zeroapi.Context) {
+		if route := ctx.Route(); route != nil && route.Meta("perm") == "admin" && ctx.Header("X-Role") != "admin" {
+			ctx.SetHTTPCode(http.StatusForbidden)
+			ctx.Stopped()
+		}
+	})
+
+	a.Get("/admin/:id", func(ctx zeroapi.Context) {
+		_, _ = ctx.Text(ctx.Route().Path() + " " + ctx.Dynamic("id"))
+	}).Meta("perm", "admin")
+	a.Get("/public", emptyHandle)
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := serve(a, zeroapi.MethodGet, "/admin/1001"); res.Code != http.StatusForbidden {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest(zeroapi.MethodGet, "/admin/1001", nil)
+	req.Header.Set("X-Role", "admin")
+	a.Server().ServeHTTP(res, req)
+	if res.Code != http.StatusOK || res.Body.String() != "/admin/:id 1001" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Body.String())
+	}
+
+	if res := serve(a, zeroapi.MethodGet, "/public"); res.Code != http.StatusOK {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+}
+
+func TestServerRewritePath(t *testing.T) {
+	a := zeroapp.NewApp()
+
+	// 中间件修改请求路径后重新匹配
+	a.Use(func(ctx zeroapi.Context) {
+		if ctx.Request().URL.Path == "/old" {
+			ctx.Request().URL.Path = "/new"
+		}
+	})
+	a.Get("/new", func(ctx zeroapi.Context) {
+		_, _ = ctx.Text(ctx.Route().Path())
+	})
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := serve(a, zeroapi.MethodGet, "/old"); res.Code != http.StatusOK || res.Body.String() != "/new" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Body.String())
+	}
+}
+
+func TestServerRewritePathDynamic(t *testing.T) {
+	a := zeroapp.NewApp()
+
+	// 中间件设置的动态参数在重新匹配后保留，原路由的参数被丢弃
+	a.Use(func(ctx zeroapi.Context) {
+		if tenant := ctx.Dynamic("tenant"); tenant != "" {
+			_ = ctx.SetDynamic("tenant", strings.ToUpper(tenant))
+			_ = ctx.SetDynamic("source", "legacy")
+			ctx.Request().URL.Path = "/users/" + ctx.Dynamic("id")
+		}
+	})
+	a.Get("/legacy/:tenant/:id", emptyHandle)
+	a.Get("/users/:id", func(ctx zeroapi.Context) {
+		params := ctx.Params().Map()
+		_, _ = ctx.Text(fmt.Sprintf("%s|%s|%s|%d", ctx.Dynamic("id"), ctx.Dynamic("tenant"), ctx.Dynamic("source"), len(params)))
+	})
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := serve(a, zeroapi.MethodGet, "/legacy/acme/1001"); res.Code != http.StatusOK || res.Body.String() != "1001|ACME|legacy|3" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Body.String())
+	}
+	if res := serve(a, zeroapi.MethodGet, "/users/1002"); res.Body.String() != "1002|||1" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Body.String())
+	}
+}
+
+func TestServerRuntimeRegister(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/user", emptyHandle)
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 200; j++ {
+				if res := serve(a, zeroapi.MethodGet, "/user"); res.Code != http.StatusOK {
+					t.Errorf("invalid code: %d", res.Code)
+					return
+				}
+				serve(a, zeroapi.MethodGet, "/plugin")
+			}
+		}()
+	}
+
+	for i := 0; i < 20; i++ {
+		a.Get("/plugin", emptyHandle)
+		if err := a.Router().Remove(zeroapi.MethodGet, "/plugin"); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	wg.Wait()
+
+	a.Get("/plugin", emptyHandle)
+	if res := serve(a, zeroapi.MethodGet, "/plugin"); res.Code != http.StatusOK {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+}
+
+func TestServerCustomMethod(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Handle("PROPFIND", "/files", func(ctx zeroapi.Context) {
+		_, _ = ctx.Text(ctx.Method())
+	})
+	a.Any("/any", func(ctx zeroapi.Context) {
+		_, _ = ctx.Text(ctx.Method())
+	})
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := serve(a, "PROPFIND", "/files"); res.Code != http.StatusOK || res.Body.String() != "PROPFIND" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Body.String())
+	}
+
+	res := serve(a, zeroapi.MethodGet, "/files")
+	if res.Code != http.StatusMethodNotAllowed || res.Header().Get("Allow") != "PROPFIND" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Header().Get("Allow"))
+	}
+
+	if res := serve(a, zeroapi.MethodDelete, "/any"); res.Body.String() != "DELETE" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Body.String())
+	}
+	if res := serve(a, zeroapi.MethodTrace, "/any"); res.Code != http.StatusMethodNotAllowed {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+}
+
+func TestServerNestedGroup(t *testing.T) {
+	var order []string
+	mark := func(name string) zeroapi.Handler {
+		return func(zeroapi.Context) {
+			order = append(order, name)
+		}
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "a.txt"), []byte("static"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	a := zeroapp.NewApp()
+	a.Use(mark("app"))
+
+	api := a.Group("/api").Use(mark("api"))
+	v1 := api.Group("/v1").Use(mark("v1"))
+	admin := v1.Group("/admin").Use(mark("admin"))
+	admin.Get("/users", mark("handler"))
+	v1.Static("/files", dir)
+
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := serve(a, zeroapi.MethodGet, "/api/v1/admin/users"); res.Code != http.StatusOK {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+	if s := strings.Join(order, ","); s != "app,api,v1,admin,handler" {
+		t.Fatalf("invalid order: %s", s)
+	}
+
+	if res := serve(a, zeroapi.MethodGet, "/api/v1/files/a.txt"); res.Code != http.StatusOK || res.Body.String() != "static" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Body.String())
+	}
+}
+
+func TestServerAPIVersion(t *testing.T) {
+	text := func(s string) zeroapi.Handler {
+		return func(ctx zeroapi.Context) {
+			_, _ = ctx.Text(s + ctx.Route().APIVersion())
+		}
+	}
+
+	register := func(a zeroapi.App) {
+		a.Get("/users", text("default"))
+		a.Get("/health", text("health"))
+		a.APIVersion("2").Get("/users", text("v"))
+		if err := a.Router().Build(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	request := func(a zeroapi.App, target, key, value string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(zeroapi.MethodGet, target, nil)
+		if key != "" {
+			req.Header.Set(key, value)
+		}
+		a.Server().ServeHTTP(res, req)
+		return res
+	}
+
+	a := zeroapp.NewApp(zeroapp.WithAPIVersionQuery("api-version"))
+	register(a)
+
+	tests := []struct {
+		target, key, value string
+		code               int
+		body               string
+	}{
+		{"/users", "", "", http.StatusOK, "default"},
+		{"/users", "Accept-Version", "2", http.StatusOK, "v2"},
+		{"/users", "Accept-Version", "v2", http.StatusOK, "v2"},
+		{"/users", "Accept", "text/html, application/vnd.app.v2+json;q=0.9", http.StatusOK, "v2"},
+		{"/users", "Accept", "application/vnd.app+json; q=0.9; version=2", http.StatusOK, "v2"},
+		{"/users?api-version=2", "", "", http.StatusOK, "v2"},
+		{"/users", "Accept-Version", "3", http.StatusOK, "default"},
+		{"/health", "Accept-Version", "2", http.StatusOK, "health"},
+	}
+	for _, test := range tests {
+		res := request(a, test.target, test.key, test.value)
+		if res.Code != test.code || res.Body.String() != test.body {
+			t.Fatalf("%s %s=%s: %d %s", test.target, test.key, test.value, res.Code, res.Body.String())
+		}
+	}
+
+	a = zeroapp.NewApp(zeroapp.WithStrictAPIVersion(true), zeroapp.WithDefaultAPIVersion("2"))
+	register(a)
+
+	tests = []struct {
+		target, key, value string
+		code               int
+		body               string
+	}{
+		{"/users", "", "", http.StatusOK, "v2"},
+		{"/health", "", "", http.StatusOK, "health"},
+		{"/users", "Accept-Version", "3", http.StatusNotAcceptable, ""},
+		// 不含版本号的厂商类型不是请求指定的版本，使用默认版本
+		{"/users", "Accept", "application/vnd.dece.video", http.StatusOK, "v2"},
+		{"/users", "Accept", "application/vnd.app.vendor+json, application/vnd.v3+json", http.StatusOK, "v2"},
+		{"/health", "Accept-Version", "2", http.StatusNotFound, ""},
+	}
+	for _, test := range tests {
+		res := request(a, test.target, test.key, test.value)
+		if res.Code != test.code || (test.body != "" && res.Body.String() != test.body) {
+			t.Fatalf("%s %s=%s: %d %s", test.target, test.key, test.value, res.Code, res.Body.String())
+		}
+	}
+}
+
+func TestServerPredicate(t *testing.T) {
+	text := func(s string) zeroapi.Handler {
+		return func(ctx zeroapi.Context) {
+			_, _ = ctx.Text(s)
+		}
+	}
+
+	a := zeroapp.NewApp()
+	a.When(zeroapi.ContentType("application/x-protobuf")).Post("/rpc", text("protobuf"))
+	a.When(zeroapi.ContentType("application/json")).Post("/rpc", text("json"))
+	a.Get("/export", text("json"))
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Build 之后注册
+	a.When(zeroapi.Queries("format", "csv")).Get("/export", text("csv"))
+
+	request := func(method, target, contentType string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(method, target, nil)
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		a.Server().ServeHTTP(res, req)
+		return res
+	}
+
+	tests := []struct {
+		method, target, contentType string
+		code                        int
+		body                        string
+	}{
+		{zeroapi.MethodPost, "/rpc", "application/x-protobuf", http.StatusOK, "protobuf"},
+		{zeroapi.MethodPost, "/rpc", "application/json; charset=utf-8", http.StatusOK, "json"},
+		{zeroapi.MethodPost, "/rpc", "text/plain", http.StatusNotFound, ""},
+		{zeroapi.MethodGet, "/rpc", "", http.StatusMethodNotAllowed, ""},
+		{zeroapi.MethodGet, "/export", "", http.StatusOK, "json"},
+		{zeroapi.MethodGet, "/export?format=csv", "", http.StatusOK, "csv"},
+	}
+	for _, test := range tests {
+		res := request(test.method, test.target, test.contentType)
+		if res.Code != test.code || (test.body != "" && res.Body.String() != test.body) {
+			t.Fatalf("%s %s %s: %d %s", test.method, test.target, test.contentType, res.Code, res.Body.String())
+		}
+	}
+}
+
+func TestServerTimeout(t *testing.T) {
+	var executed atomic.Bool
+
+	slow := func(ctx zeroapi.Context) {
+		ctx.SetHeader("X-Slow", "1")
+		<-ctx.Request().Context().Done()
+		_, _ = ctx.Text("late")
+	}
+	next := func(ctx zeroapi.Context) {
+		executed.Store(true)
+	}
+
+	a := zeroapp.NewApp(zeroapp.WithTimeoutStatus(http.StatusGatewayTimeout))
+	a.Get("/slow", slow, next).Timeout(20 * time.Millisecond)
+	a.Get("/fast", func(ctx zeroapi.Context) {
+		ctx.SetHeader("X-Fast", "1")
+		_, _ = ctx.Text("fast")
+	}).Timeout(time.Second)
+	g := a.Group("/report").UseTimeout(20 * time.Millisecond)
+	g.Get("/daily", slow)
+	g.Get("/monthly", slow).Timeout(30 * time.Millisecond)
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, target := range []string{"/slow", "/report/daily", "/report/monthly"} {
+		res := serve(a, zeroapi.MethodGet, target)
+		if res.Code != http.StatusGatewayTimeout || strings.Contains(res.Body.String(), "late") || res.Header().Get("X-Slow") != "" {
+			t.Fatalf("%s: %d %s", target, res.Code, res.Body.String())
+		}
+	}
+	if executed.Load() {
+		t.Fatal("handlers after timeout should not be executed")
+	}
+
+	res := serve(a, zeroapi.MethodGet, "/fast")
+	if res.Code != http.StatusOK || res.Body.String() != "fast" || res.Header().Get("X-Fast") != "1" {
+		t.Fatalf("fast: %d %s", res.Code, res.Body.String())
+	}
+
+	timeouts := make(map[string]time.Duration)
+	for _, info := range a.Router().Routes() {
+		timeouts[info.Path] = info.Timeout
+	}
+	if timeouts["/report/daily"] != 20*time.Millisecond || timeouts["/report/monthly"] != 30*time.Millisecond {
+		t.Fatalf("invalid timeouts: %v", timeouts)
+	}
+}
+
+func TestServerTimeoutFlush(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get("/slow", func(ctx zeroapi.Context) {
+		// 不检查 context，超时后仍然继续执行
+		time.Sleep(600 * time.Millisecond)
+	}).Timeout(50 * time.Millisecond)
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	ts := httptest.NewServer(a.Server())
+	defer ts.Close()
+
+	start := time.Now()
+	res, err := http.Get(ts.URL + "/slow")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > 300*time.Millisecond {
+		t.Fatalf("timeout response should not wait for the handler: %s", elapsed)
+	}
+	if res.StatusCode != http.StatusServiceUnavailable || !strings.Contains(string(body), "503") {
+		t.Fatalf("%d %s", res.StatusCode, body)
+	}
+}
+
+func TestServerUseRawPath(t *testing.T) {
+	register := func(a zeroapi.App) {
+		a.Get("/objects/:key", func(ctx zeroapi.Context) {
+			_, _ = ctx.Text(ctx.Dynamic("key"))
+		})
+		a.Get("/objects/:key/versions/:version", func(ctx zeroapi.Context) {
+			_, _ = ctx.Text(ctx.Dynamic("key") + "|" + ctx.Dynamic("version"))
+		})
+		a.Get("/files/*filepath", func(ctx zeroapi.Context) {
+			_, _ = ctx.Text(ctx.Dynamic("filepath"))
+		})
+		a.Get("/keys/:key(^a.b$)", func(ctx zeroapi.Context) {
+			_, _ = ctx.Text(ctx.Dynamic("key"))
+		})
+		a.Get("/names/:name|len(1,5)|", func(ctx zeroapi.Context) {
+			_, _ = ctx.Text(ctx.Dynamic("name"))
+		})
+		if err := a.Router().Build(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	a := zeroapp.NewApp(zeroapp.WithUseRawPath(true))
+	register(a)
+
+	tests := []struct {
+		target string
+		body   string
+	}{
+		{"/objects/a%2Fb", "a/b"},
+		{"/objects/a%20b", "a b"},
+		{"/objects/x%2Fy/versions/v%201", "x/y|v 1"},
+		{"/objects/plain", "plain"},
+		{"/files/a%2Fb/c%20d", "a%2Fb/c%20d"},
+		// 先解码，再检查正则表达式和验证函数
+		{"/keys/a%2Fb", "a/b"},
+		{"/names/%C3%A9t%C3%A9", "été"},
+	}
+	for _, test := range tests {
+		res := serve(a, zeroapi.MethodGet, test.target)
+		if res.Code != http.StatusOK || res.Body.String() != test.body {
+			t.Fatalf("%s: %d %s", test.target, res.Code, res.Body.String())
+		}
+	}
+
+	// 默认使用解码后的路径，%2F 被视为路径分隔符
+	a = zeroapp.NewApp()
+	register(a)
+
+	if res := serve(a, zeroapi.MethodGet, "/objects/a%2Fb"); res.Code != http.StatusNotFound {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+	if res := serve(a, zeroapi.MethodGet, "/files/a%2Fb/c%20d"); res.Body.String() != "a/b/c d" {
+		t.Fatalf("invalid body: %s", res.Body.String())
+	}
+}
+
+func TestServerUseRawPathNotMatched(t *testing.T) {
+	a := zeroapp.NewApp(zeroapp.WithUseRawPath(true), zeroapp.WithRedirectTrailingSlash(true), zeroapp.WithRedirectFixedPath(true))
+	a.Get("/objects/:key(^a.b$)", emptyHandle)
+	a.Get("/names/:name|len(1,5)|", emptyHandle)
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	// 检查其它 Method 时同样先解码再检查正则表达式和验证函数
+	res := serve(a, zeroapi.MethodPost, "/objects/a%2Fb")
+	if res.Code != http.StatusMethodNotAllowed || res.Header().Get("Allow") != "GET" {
+		t.Fatalf("invalid response: %d %s", res.Code, res.Header().Get("Allow"))
+	}
+
+	tests := []struct {
+		target   string
+		location string
+	}{
+		{"/objects/a%2Fb/", "/objects/a%2Fb"},
+		{"/objects//a%2Fb", "/objects/a%2Fb"},
+		{"/names/%C3%A9t%C3%A9/", "/names/%C3%A9t%C3%A9"},
+	}
+	for _, test := range tests {
+		res := serve(a, zeroapi.MethodGet, test.target)
+		if res.Code != http.StatusMovedPermanently || res.Header().Get("Location") != test.location {
+			t.Fatalf("%s: %d %s", test.target, res.Code, res.Header().Get("Location"))
+		}
+	}
+}
+
+func TestServerDynamicCaptures(t *testing.T) {
+	a := zeroapp.NewApp()
+	a.Get(`/archive/:date(^(?P<year>\d{4})-(?P<month>\d{2})$)`, func(ctx zeroapi.Context) {
+		_, _ = ctx.Text(ctx.Dynamic("date") + "|" + ctx.Dynamic("year") + "|" + ctx.Dynamic("month"))
+	})
+	if err := a.Router().Build(); err != nil {
+		t.Fatal(err)
+	}
+
+	if res := serve(a, zeroapi.MethodGet, "/archive/2024-05"); res.Code != http.StatusOK || res.Body.String() != "2024-05|2024|05" {
+		t.Fatalf("%d %s", res.Code, res.Body.String())
+	}
+	if res := serve(a, zeroapi.MethodGet, "/archive/2024"); res.Code != http.StatusNotFound {
+		t.Fatalf("invalid code: %d", res.Code)
+	}
+}
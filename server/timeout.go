@@ -0,0 +1,218 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// timeoutWriter 路由超时后丢弃之后的写入，防止与超时响应冲突
+// 路由处理函数使用独立的响应头，写入状态码时才复制到原始响应中
+type timeoutWriter struct {
+	http.ResponseWriter
+
+	mu sync.Mutex
+
+	// header 路由处理函数使用的响应头
+	header http.Header
+
+	// wroteHeader 是否已经写入状态码
+	wroteHeader bool
+
+	// timedOut 是否已经超时，超时后的写入返回 http.ErrHandlerTimeout
+	timedOut bool
+
+	// done 路由处理函数执行完成，之后不再处理超时
+	done bool
+
+	// ctx 带有超时时间的 context
+	ctx context.Context
+
+	// code 超时时返回的状态码
+	code int
+}
+
+func newTimeoutWriter(ctx context.Context, w http.ResponseWriter, code int) *timeoutWriter {
+	return &timeoutWriter{ResponseWriter: w, header: w.Header().Clone(), ctx: ctx, code: code}
+}
+
+func (w *timeoutWriter) Header() http.Header {
+	return w.header
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.checkTimeout() || w.wroteHeader {
+		return
+	}
+
+	w.writeHeader(code)
+}
+
+func (w *timeoutWriter) Write(b []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.checkTimeout() {
+		return 0, http.ErrHandlerTimeout
+	}
+
+	if !w.wroteHeader {
+		w.writeHeader(http.StatusOK)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *timeoutWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.checkTimeout() {
+		return
+	}
+
+	if !w.wroteHeader {
+		w.writeHeader(http.StatusOK)
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// writeHeader 复制响应头并写入状态码，调用前需要持有 mu
+func (w *timeoutWriter) writeHeader(code int) {
+	dst := w.ResponseWriter.Header()
+	for key := range dst {
+		delete(dst, key)
+	}
+	for key, values := range w.header {
+		dst[key] = values
+	}
+
+	w.wroteHeader = true
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// timeout 超时后由 context.AfterFunc 调用
+func (w *timeoutWriter) timeout() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if !w.done {
+		w.checkTimeout()
+	}
+}
+
+// checkTimeout 检查是否已经超时，第一次发现超时时写入超时响应，调用前需要持有 mu
+// context 取消与 context.AfterFunc 执行之间，路由处理函数可能先写入，因此每次写入前都需要检查
+// 已经写入状态码时只丢弃之后的写入
+func (w *timeoutWriter) checkTimeout() bool {
+	if w.timedOut {
+		return true
+	}
+
+	if !errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		return false
+	}
+
+	w.timedOut = true
+
+	if !w.wroteHeader {
+		body := []byte(`{"code":"` + strconv.Itoa(w.code) + `","message":"` + strings.ToUpper(http.StatusText(w.code)) + `"}`)
+
+		// 设置 Content-Length 并立即发送，客户端不需要等待路由处理函数返回
+		header := w.ResponseWriter.Header()
+		header.Set("Content-Type", "application/json;charset=utf-8")
+		header.Set("Content-Length", strconv.Itoa(len(body)))
+		w.ResponseWriter.WriteHeader(w.code)
+		_, _ = w.ResponseWriter.Write(body)
+
+		if f, ok := w.ResponseWriter.(http.Flusher); ok {
+			f.Flush()
+		}
+	}
+
+	// 超时响应已经完成，之后不再写入
+	w.wroteHeader = true
+	w.done = true
+
+	return true
+}
+
+// finish 路由处理函数执行完成，返回是否已经超时
+func (w *timeoutWriter) finish() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.done = true
+
+	return w.checkTimeout()
+}
+
+// isTimedOut 是否已经超时
+func (w *timeoutWriter) isTimedOut() bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.checkTimeout()
+}
+
+// runHandlers 执行路由处理函数和路由级别中间件，全部执行完成时返回 true
+// 设置了超时时间时，使用 context.WithTimeout 替换请求的 context，超时后返回 app.WithTimeoutStatus 设置的状态码
+// 路由处理函数仍然同步执行，需要自行检查 ctx.Request().Context().Done()，超时后的写入会被丢弃，之后的处理函数不再执行
+func (s *server) runHandlers(ctx zeroapi.Context, route zeroapi.MatchedRoute) (ok bool) {
+	timeout := route.Timeout()
+	if timeout <= 0 {
+		return execute(ctx, route.Handlers(), nil)
+	}
+
+	c, cancel := context.WithTimeout(ctx.Request().Context(), timeout)
+	ctx.SetRequest(ctx.Request().WithContext(c))
+
+	code := s.app.TimeoutStatus()
+
+	w := newTimeoutWriter(c, ctx.Response().Writer(), code)
+	ctx.Response().SetWriter(w)
+
+	// 客户端断开连接时不会写入超时响应
+	stop := context.AfterFunc(c, w.timeout)
+
+	defer func() {
+		stop()
+
+		if w.finish() {
+			// 只记录状态码，写入会被丢弃
+			ctx.SetHTTPCode(code)
+			ok = false
+		}
+
+		cancel()
+	}()
+
+	return execute(ctx, route.Handlers(), w)
+}
+
+// execute 依次执行 handlers，停止或者超时时返回 false
+func execute(ctx zeroapi.Context, handlers []zeroapi.Handler, w *timeoutWriter) bool {
+	for _, handler := range handlers {
+		if handler == nil {
+			continue
+		}
+
+		handler(ctx)
+		if ctx.IsStopped() || (w != nil && w.isTimedOut()) {
+			return false
+		}
+	}
+
+	return true
+}
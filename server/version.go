@@ -0,0 +1,93 @@
+package server
+
+import (
+	"strings"
+
+	zeroapi "github.com/zerogo-hub/zero-api"
+)
+
+// requestVersion 获取请求的 API 版本，explicit 表示由请求指定，而不是默认版本
+// 依次检查:
+// 1. Accept-Version: 2
+// 2. Accept: application/vnd.app.v2+json
+// 3. 查询参数，见 app.WithAPIVersionQuery
+func (s *server) requestVersion(ctx zeroapi.Context) (version string, explicit bool) {
+	if version = strings.TrimSpace(ctx.Header("Accept-Version")); version != "" {
+		return version, true
+	}
+
+	if version = mediaTypeVersion(ctx.Header("Accept")); version != "" {
+		return version, true
+	}
+
+	if name := s.app.APIVersionQuery(); name != "" {
+		if version = ctx.Query(name); version != "" {
+			return version, true
+		}
+	}
+
+	return s.app.DefaultAPIVersion(), false
+}
+
+// mediaTypeVersion 从 Accept 中获取 API 版本，依次检查每一个媒体类型
+// 1. 厂商类型以 .v + 版本号结尾，例如: application/vnd.app.v2+json -> 2
+// 2. version 参数，例如: application/vnd.app+json; version=2 -> 2
+// 版本号由数字和 "." 组成，例如 application/vnd.dece.video 不含版本号
+func mediaTypeVersion(accept string) string {
+	for accept != "" {
+		var mediaType, params string
+		mediaType, accept, _ = strings.Cut(accept, ",")
+		mediaType, params, _ = strings.Cut(mediaType, ";")
+
+		mediaType = strings.ToLower(strings.TrimSpace(mediaType))
+		if subtype, ok := strings.CutPrefix(mediaType, "application/vnd."); ok {
+			subtype, _, _ = strings.Cut(subtype, "+")
+
+			// 厂商名称不可以为空
+			if pos := strings.LastIndex(subtype, ".v"); pos > 0 && isVersionNumber(subtype[pos+2:]) {
+				return subtype[pos+2:]
+			}
+		}
+
+		if version := versionParam(params); version != "" {
+			return version
+		}
+	}
+
+	return ""
+}
+
+// versionParam 从媒体类型参数中获取 version，例如 q=0.9; version=2 -> 2
+func versionParam(params string) string {
+	for params != "" {
+		var param string
+		param, params, _ = strings.Cut(params, ";")
+
+		key, value, _ := strings.Cut(param, "=")
+		if !strings.EqualFold(strings.TrimSpace(key), "version") {
+			continue
+		}
+
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+		if value = strings.TrimPrefix(strings.TrimPrefix(value, "v"), "V"); isVersionNumber(value) {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// isVersionNumber 是否由数字和 "." 组成，并且以数字开头和结尾，例如 2，2.1
+func isVersionNumber(s string) bool {
+	if s == "" || s[0] == '.' || s[len(s)-1] == '.' {
+		return false
+	}
+
+	for i := 0; i < len(s); i++ {
+		if (s[i] < '0' || s[i] > '9') && s[i] != '.' {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,95 @@
+package server
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// headWriter 使用 GET 路由处理 HEAD 请求时，丢弃响应内容，只记录大小
+// 状态码延迟到 finish 时写入，以便设置 Content-Length
+type headWriter struct {
+	http.ResponseWriter
+
+	// code 记录的 http 状态码
+	code int
+
+	// size 丢弃的响应内容大小
+	size int
+}
+
+func (w *headWriter) WriteHeader(code int) {
+	if w.code == 0 {
+		w.code = code
+	}
+}
+
+func (w *headWriter) Write(b []byte) (int, error) {
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	w.size += len(b)
+
+	return len(b), nil
+}
+
+// finish 设置 Content-Length，写入状态码
+func (w *headWriter) finish() {
+	if w.size > 0 && w.Header().Get("Content-Length") == "" {
+		w.Header().Set("Content-Length", strconv.Itoa(w.size))
+	}
+
+	if w.code == 0 {
+		w.code = http.StatusOK
+	}
+
+	w.ResponseWriter.WriteHeader(w.code)
+}
+
+// statusWriter 延迟写入默认状态码，直到第一次写入响应内容或者调用 finish
+// 之前设置的响应头都会生效，路由处理函数也可以自行写入其它状态码
+type statusWriter struct {
+	http.ResponseWriter
+
+	// code 默认状态码
+	code int
+
+	// status 实际写入的状态码，0 表示还未写入
+	status int
+}
+
+func (w *statusWriter) WriteHeader(code int) {
+	if w.status != 0 {
+		return
+	}
+
+	w.status = code
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *statusWriter) Write(b []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(w.code)
+	}
+
+	return w.ResponseWriter.Write(b)
+}
+
+func (w *statusWriter) Flush() {
+	if w.status == 0 {
+		w.WriteHeader(w.code)
+	}
+
+	if f, ok := w.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+// finish 还未写入时写入默认状态码，返回实际写入的状态码
+func (w *statusWriter) finish() int {
+	if w.status == 0 {
+		w.WriteHeader(w.code)
+	}
+
+	return w.status
+}